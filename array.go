@@ -0,0 +1,131 @@
+package spretty
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// maxArrayPeek bounds how far ahead the Scanner looks for the first element
+// of a JSON array.
+const maxArrayPeek = 4096
+
+// startsArray reports whether the input starts with a JSON array of objects,
+// as log downloads from web consoles often do, rather than with lines.
+func startsArray(br *bufio.Reader) bool {
+	bracket := false
+	for n := 1; n <= maxArrayPeek; n++ {
+		b, err := br.Peek(n)
+		if err != nil {
+			return false
+		}
+		switch c := b[n-1]; {
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+		case !bracket && c == '[':
+			bracket = true
+		default:
+			return bracket && (c == '{' || c == ']')
+		}
+	}
+	return false
+}
+
+// scanArray streams the elements of a JSON array through processLine one at
+// a time, so that the array never has to fit in memory. It returns once the
+// array and the rest of its line have been read.
+func (s *Scanner) scanArray(br *bufio.Reader, w io.Writer) error {
+	if err := skipArrayStart(br); err != nil {
+		return err
+	}
+	for {
+		more, err := s.nextElement(br, w)
+		if err != nil || !more {
+			return err
+		}
+	}
+}
+
+// skipArrayStart reads up to and including the opening bracket.
+func skipArrayStart(br *bufio.Reader) error {
+	for {
+		c, err := br.ReadByte()
+		if err != nil {
+			return fmt.Errorf("reading array: %w", err)
+		}
+		if c == '[' {
+			return nil
+		}
+	}
+}
+
+// nextElement reads and formats the next array element. It reports false
+// once the array has ended.
+func (s *Scanner) nextElement(br *bufio.Reader, w io.Writer) (bool, error) {
+	var t depthTracker
+	elem := s.line[:0]
+	n := 0
+	for {
+		c, err := br.ReadByte()
+		if err != nil {
+			s.line = elem
+			if procErr := s.processElement(w, elem, n); procErr != nil {
+				return false, procErr
+			}
+			if errors.Is(err, io.EOF) {
+				return false, nil
+			}
+			return false, fmt.Errorf("reading array: %w", err)
+		}
+
+		if t.depth == 0 && !t.inString && (c == ',' || c == ']') {
+			s.line = elem
+			if err := s.processElement(w, elem, n); err != nil {
+				return false, err
+			}
+			if c == ']' {
+				return false, skipLine(br)
+			}
+			return true, nil
+		}
+
+		t.feedByte(c)
+		n++
+		if n <= s.maxLineSize() {
+			elem = append(elem, c)
+		}
+	}
+}
+
+// processElement formats one array element of n bytes.
+func (s *Scanner) processElement(w io.Writer, elem []byte, n int) error {
+	elem = bytes.TrimSpace(elem)
+	switch {
+	case n == 0 || len(elem) == 0:
+		return nil
+	case n > s.maxLineSize():
+		return s.processOversized(w, elem, n)
+	default:
+		return s.processLine(w, elem)
+	}
+}
+
+// skipLine discards the rest of the current line if it holds only whitespace.
+func skipLine(br *bufio.Reader) error {
+	for {
+		b, err := br.Peek(1)
+		if err != nil {
+			return nil //nolint:nilerr // the line loop reports read errors
+		}
+		switch b[0] {
+		case ' ', '\t', '\r':
+			_, _ = br.ReadByte()
+		case '\n':
+			_, _ = br.ReadByte()
+			return nil
+		default:
+			return nil
+		}
+	}
+}
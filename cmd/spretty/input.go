@@ -0,0 +1,103 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+)
+
+var (
+	gzipMagic  = []byte{0x1f, 0x8b}
+	bzip2Magic = []byte("BZh")
+	zstdMagic  = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+// input is an opened file argument, decompressed if needed.
+type input struct {
+	io.Reader
+
+	closers []func() error
+}
+
+func (in *input) Close() error {
+	var errs []error
+	for i := len(in.closers) - 1; i >= 0; i-- {
+		errs = append(errs, in.closers[i]())
+	}
+	return errors.Join(errs...)
+}
+
+// openInput opens the file at path, decompressing gzip, bzip2 and zstd
+// content detected by its magic bytes. zstd needs the zstd command, since
+// the standard library has no zstd decoder.
+func openInput(path string) (*input, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening input: %w", err)
+	}
+	in := &input{closers: []func() error{f.Close}}
+
+	br := bufio.NewReader(f)
+	magic, err := br.Peek(len(zstdMagic))
+	if err != nil && !errors.Is(err, io.EOF) {
+		_ = in.Close()
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+
+	switch {
+	case bytes.HasPrefix(magic, gzipMagic):
+		zr, err := gzip.NewReader(br)
+		if err != nil {
+			_ = in.Close()
+			return nil, fmt.Errorf("reading gzip %s: %w", path, err)
+		}
+		in.Reader = zr
+		in.closers = append(in.closers, zr.Close)
+	case bytes.HasPrefix(magic, bzip2Magic):
+		in.Reader = bzip2.NewReader(br)
+	case bytes.HasPrefix(magic, zstdMagic):
+		r, wait, err := zstdReader(br)
+		if err != nil {
+			_ = in.Close()
+			return nil, fmt.Errorf("reading zstd %s: %w", path, err)
+		}
+		in.Reader = r
+		in.closers = append(in.closers, wait)
+	default:
+		in.Reader = br
+	}
+	return in, nil
+}
+
+// zstdReader decompresses r with the zstd command.
+func zstdReader(r io.Reader) (io.Reader, func() error, error) {
+	bin, err := exec.LookPath("zstd")
+	if err != nil {
+		return nil, nil, fmt.Errorf("zstd input needs the zstd command: %w", err)
+	}
+	cmd := exec.Command(bin, "-dc") //nolint:noctx // runs for as long as the input is read
+	cmd.Stdin = r
+	cmd.Stderr = os.Stderr
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, nil, fmt.Errorf("starting zstd: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, nil, fmt.Errorf("starting zstd: %w", err)
+	}
+	wait := func() error {
+		// Closing the pipe first stops zstd if the input was not read to the end.
+		_ = out.Close()
+		if err := cmd.Wait(); err != nil {
+			return fmt.Errorf("zstd: %w", err)
+		}
+		return nil
+	}
+	return out, wait, nil
+}
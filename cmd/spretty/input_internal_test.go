@@ -0,0 +1,156 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/hex"
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+const record = `{"level":"INFO","msg":"hi"}` + "\n"
+
+// bzip2Record is record compressed with bzip2, which the standard library
+// cannot write.
+const bzip2Record = "425a683931415926535973e3c88a00000d5d80001010040010012182e6090a2000222340d0d0dea8534c8c4c4c41b9bc" +
+	"20ddcc9e6ee68b442a0a53e2ee48a70a120e7c791140"
+
+func TestOpenInput(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		data    func(t *testing.T) []byte
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "plain",
+			data: func(*testing.T) []byte { return []byte(record) },
+			want: record,
+		},
+		{
+			name: "empty",
+			data: func(*testing.T) []byte { return nil },
+			want: "",
+		},
+		{
+			name: "shorter than magic bytes",
+			data: func(*testing.T) []byte { return []byte("{}") },
+			want: "{}",
+		},
+		{
+			name: "gzip",
+			data: func(t *testing.T) []byte {
+				t.Helper()
+				var buf bytes.Buffer
+				zw := gzip.NewWriter(&buf)
+				if _, err := zw.Write([]byte(record)); err != nil {
+					t.Fatal(err)
+				}
+				if err := zw.Close(); err != nil {
+					t.Fatal(err)
+				}
+				return buf.Bytes()
+			},
+			want: record,
+		},
+		{
+			name:    "corrupt gzip",
+			data:    func(*testing.T) []byte { return []byte{0x1f, 0x8b, 0x00, 0x00} },
+			wantErr: true,
+		},
+		{
+			name: "bzip2",
+			data: func(t *testing.T) []byte {
+				t.Helper()
+				b, err := hex.DecodeString(bzip2Record)
+				if err != nil {
+					t.Fatal(err)
+				}
+				return b
+			},
+			want: record,
+		},
+		{
+			name: "zstd",
+			data: func(t *testing.T) []byte {
+				t.Helper()
+				if _, err := exec.LookPath("zstd"); err != nil {
+					t.Skip("zstd command not found")
+				}
+				cmd := exec.CommandContext(t.Context(), "zstd", "-c")
+				cmd.Stdin = bytes.NewReader([]byte(record))
+				b, err := cmd.Output()
+				if err != nil {
+					t.Fatal(err)
+				}
+				return b
+			},
+			want: record,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			path := filepath.Join(t.TempDir(), "app.log")
+			if err := os.WriteFile(path, tt.data(t), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			in, err := openInput(path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("openInput() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			got, err := io.ReadAll(in)
+			if err != nil {
+				t.Fatalf("reading input: %v", err)
+			}
+			if err := in.Close(); err != nil {
+				t.Errorf("Close() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("read %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOpenInput_Missing(t *testing.T) {
+	t.Parallel()
+
+	if _, err := openInput(filepath.Join(t.TempDir(), "missing.log")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("openInput() error = %v, want %v", err, os.ErrNotExist)
+	}
+}
+
+func TestInput_Close(t *testing.T) {
+	t.Parallel()
+
+	var order []string
+	closer := func(name string, err error) func() error {
+		return func() error {
+			order = append(order, name)
+			return err
+		}
+	}
+	errDecoder := errors.New("decoder failed")
+	in := &input{closers: []func() error{closer("file", nil), closer("decoder", errDecoder)}}
+
+	if err := in.Close(); !errors.Is(err, errDecoder) {
+		t.Errorf("Close() error = %v, want %v", err, errDecoder)
+	}
+	if want := []string{"decoder", "file"}; !slices.Equal(order, want) {
+		t.Errorf("closed %v, want %v", order, want)
+	}
+}
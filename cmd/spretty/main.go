@@ -1,10 +1,15 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
+	"io"
+	"log/slog"
 	"os"
+	"os/signal"
 	"strings"
+	"syscall"
 
 	spretty "github.com/mickamy/slog-pretty"
 )
@@ -16,14 +21,39 @@ func main() {
 	fs.Usage = func() {
 		fmt.Fprintf(os.Stderr, "spretty — pretty-print slog JSON output\n\n")
 		fmt.Fprintf(os.Stderr, "Usage:\n")
-		fmt.Fprintf(os.Stderr, "  <command> | spretty [flags]\n\n")
+		fmt.Fprintf(os.Stderr, "  <command> | spretty [flags]\n")
+		fmt.Fprintf(os.Stderr, "  spretty [flags] file...\n")
+		fmt.Fprintf(os.Stderr, "  spretty -f [-n records] [flags] file\n\n")
+		fmt.Fprintf(os.Stderr, "Several files are merged in time order, labelled with their names.\n")
+		fmt.Fprintf(os.Stderr, "Files compressed with gzip, bzip2 or zstd are decompressed;\n")
+		fmt.Fprintf(os.Stderr, "zstd files need the zstd command on PATH.\n\n")
 		fmt.Fprintf(os.Stderr, "Flags:\n")
 		fs.PrintDefaults()
 	}
 
 	timeFormat := fs.String("time-format", "15:04:05.000", "Go time format for timestamps")
 	noColor := fs.Bool("no-color", false, "disable colored output")
+	themeName := fs.String("theme", "dark", "color theme: dark, light or high-contrast")
 	ignore := fs.String("ignore", "", "comma-separated keys to omit")
+	sortKeys := fs.Bool("sort-keys", false, "sort keys of nested objects alphabetically")
+	arrayWidth := fs.Int("array-width", 60, "widest array of scalars in bytes kept inline; 0 lists every array")
+	compact := fs.Bool("compact", false, "write each record on one line with inline key=value attrs")
+	expandJSON := fs.Bool("expand-json", false, "expand string values holding JSON into nested values")
+	timeKey := fs.String("time-key", "time", "key holding the record time")
+	levelKey := fs.String("level-key", "level", "key holding the record level")
+	msgKey := fs.String("msg-key", "msg", "key holding the record message")
+	sourceKey := fs.String("source-key", "source", "key holding the source location")
+	var timeLayouts stringList
+	fs.Var(&timeLayouts, "time-layout", "Go time layout for parsing non-RFC3339 times (repeatable)")
+	minLevel := fs.String("level", "", "minimum level to show, e.g. INFO or WARN+2")
+	format := fs.String("format", "auto", "log dialect: auto, slog, zap, zerolog or logrus")
+	envelope := fs.String("envelope", "", "dotted path of records nested in an envelope, e.g. jsonPayload")
+	keepEnvelope := fs.Bool("keep-envelope", false, "keep the envelope's other fields as attributes")
+	journald := fs.Bool("journald", false, "read journalctl -o json entries")
+	maxLineSize := fs.Int("max-line-size", 1024*1024, "longest line in bytes read in full")
+	explain := fs.Bool("explain", false, "annotate passed-through lines with why they are not records")
+	follow := fs.Bool("f", false, "follow the file like tail -F, surviving rotation")
+	tailRecords := fs.Int("n", 10, "with -f, number of records to show before following")
 	showVersion := fs.Bool("version", false, "show version and exit")
 	fs.BoolVar(showVersion, "V", false, "show version and exit (shorthand)")
 
@@ -34,13 +64,70 @@ func main() {
 		return
 	}
 
+	f, err := spretty.ParseFormat(*format)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "spretty: %v\n", err)
+		os.Exit(2)
+	}
+
+	theme, err := spretty.ParseTheme(*themeName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "spretty: %v\n", err)
+		os.Exit(2)
+	}
+
 	var opts []spretty.Option
-	opts = append(opts, spretty.WithTimeFormat(*timeFormat))
+	opts = append(opts,
+		spretty.WithTimeFormat(*timeFormat),
+		spretty.WithTimeKey(*timeKey),
+		spretty.WithLevelKey(*levelKey),
+		spretty.WithMessageKey(*msgKey),
+		spretty.WithSourceKey(*sourceKey),
+		spretty.WithFormat(f),
+		spretty.WithTimeLayouts(timeLayouts...),
+		spretty.WithEnvelope(*envelope),
+		spretty.WithMaxLineSize(*maxLineSize),
+		spretty.WithArrayWidth(*arrayWidth),
+		spretty.WithTheme(theme),
+	)
 
 	if *noColor || os.Getenv("NO_COLOR") != "" || !isTerminal(os.Stdout) {
 		opts = append(opts, spretty.WithNoColor())
 	}
 
+	if *minLevel != "" {
+		var l slog.Level
+		if err := l.UnmarshalText([]byte(*minLevel)); err != nil {
+			fmt.Fprintf(os.Stderr, "spretty: invalid --level: %v\n", err)
+			os.Exit(2)
+		}
+		opts = append(opts, spretty.WithMinLevel(l))
+	}
+
+	if *sortKeys {
+		opts = append(opts, spretty.WithSortKeys())
+	}
+
+	if *compact {
+		opts = append(opts, spretty.WithCompact())
+	}
+
+	if *expandJSON {
+		opts = append(opts, spretty.WithExpandJSON())
+	}
+
+	if *keepEnvelope {
+		opts = append(opts, spretty.WithKeepEnvelope())
+	}
+
+	if *journald {
+		opts = append(opts, spretty.WithJournald())
+	}
+
+	if *explain {
+		opts = append(opts, spretty.WithExplain())
+	}
+
 	if *ignore != "" {
 		rawKeys := strings.Split(*ignore, ",")
 		var keys []string
@@ -56,12 +143,90 @@ func main() {
 	}
 
 	s := spretty.NewScanner(opts...)
-	if err := s.Scan(os.Stdin, os.Stdout); err != nil {
+	if *follow {
+		if fs.NArg() != 1 {
+			fmt.Fprintf(os.Stderr, "spretty: -f needs exactly one file\n")
+			os.Exit(2)
+		}
+		err = followFile(s, fs.Arg(0), *tailRecords)
+	} else {
+		err = run(s, fs.Args())
+	}
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "spretty: %v\n", err)
 		os.Exit(1)
 	}
 }
 
+// run formats the named files, or stdin if there are none. Several files
+// are merged into one stream ordered by time.
+func run(s *spretty.Scanner, paths []string) error {
+	switch len(paths) {
+	case 0:
+		return s.Scan(os.Stdin, os.Stdout) //nolint:wrapcheck // Scan errors are already wrapped
+	case 1:
+		return scanFile(s, paths[0])
+	default:
+		return mergeFiles(s, paths)
+	}
+}
+
+func mergeFiles(s *spretty.Scanner, paths []string) error {
+	inputs := make([]spretty.Input, 0, len(paths))
+	var closers []io.Closer
+	defer func() {
+		for _, c := range closers {
+			_ = c.Close()
+		}
+	}()
+
+	for _, path := range paths {
+		if path == "-" {
+			inputs = append(inputs, spretty.Input{Name: "stdin", Reader: os.Stdin})
+			continue
+		}
+		in, err := openInput(path)
+		if err != nil {
+			return err
+		}
+		closers = append(closers, in)
+		inputs = append(inputs, spretty.Input{Name: path, Reader: in})
+	}
+
+	return s.Merge(os.Stdout, inputs...) //nolint:wrapcheck // Merge errors name the failing input
+}
+
+// followFile formats the file at path like tail -F until interrupted.
+func followFile(s *spretty.Scanner, path string, records int) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	r, err := spretty.Follow(ctx, path, records)
+	if err != nil {
+		return err //nolint:wrapcheck // Follow errors are already wrapped
+	}
+	defer func() { _ = r.Close() }()
+	return s.Scan(r, os.Stdout) //nolint:wrapcheck // Scan errors are already wrapped
+}
+
+func scanFile(s *spretty.Scanner, path string) error {
+	if path == "-" {
+		return s.Scan(os.Stdin, os.Stdout) //nolint:wrapcheck // Scan errors are already wrapped
+	}
+	in, err := openInput(path)
+	if err != nil {
+		return err
+	}
+	if err := s.Scan(in, os.Stdout); err != nil {
+		_ = in.Close()
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	if err := in.Close(); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
 func isTerminal(f *os.File) bool {
 	stat, err := f.Stat()
 	if err != nil {
@@ -69,3 +234,15 @@ func isTerminal(f *os.File) bool {
 	}
 	return (stat.Mode() & os.ModeCharDevice) != 0
 }
+
+// stringList is a flag.Value collecting every occurrence of a repeatable flag.
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ", ")
+}
+
+func (l *stringList) Set(v string) error {
+	*l = append(*l, v)
+	return nil
+}
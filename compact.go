@@ -0,0 +1,63 @@
+package spretty
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// writeInlineAttr writes an attr on the current line for compact output.
+// Nested objects are flattened into one pair per leaf, with dotted keys.
+func (f *Formatter) writeInlineAttr(b *bytes.Buffer, key string, v any) {
+	switch v := v.(type) {
+	case Group:
+		if len(v) > 0 {
+			for _, a := range f.ordered(v) {
+				f.writeInlineAttr(b, key+"."+a.Key, a.Value)
+			}
+			return
+		}
+	case map[string]any:
+		if len(v) > 0 {
+			for _, k := range sortedKeys(v) {
+				f.writeInlineAttr(b, key+"."+k, v[k])
+			}
+			return
+		}
+	case string:
+		if f.cfg.expandJSON {
+			if jv, ok := decodeJSONString(v); ok {
+				f.writeInlineAttr(b, key, jv)
+				return
+			}
+		}
+	}
+
+	b.WriteByte(' ')
+	f.writeColored(b, key, f.cfg.theme.Key)
+	f.writeColored(b, "=", f.cfg.theme.Equals)
+	f.writeColored(b, quoteInline(f.formatInline(v)), f.valueColor(v))
+}
+
+// formatInline formats a value that is not flattened any further.
+func (f *Formatter) formatInline(v any) string {
+	switch v := v.(type) {
+	case Group, map[string]any:
+		return "{}"
+	default:
+		return f.formatScalar(v)
+	}
+}
+
+// quoteInline quotes s if it would not read back as a single value.
+func quoteInline(s string) string {
+	if s == "" || strings.ContainsFunc(s, needsQuote) {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+func needsQuote(r rune) bool {
+	return unicode.IsSpace(r) || r == '"' || r == '=' || !unicode.IsPrint(r)
+}
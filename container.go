@@ -0,0 +1,169 @@
+package spretty
+
+import (
+	"bytes"
+	"io"
+	"time"
+)
+
+// Streams a container runtime captures output from.
+const (
+	streamStdout = "stdout"
+	streamStderr = "stderr"
+)
+
+var dockerPrefix = []byte(`{"log":`) //nolint:gochecknoglobals // constant byte slice
+
+// containerLine is an application line unwrapped from a container log file.
+type containerLine struct {
+	text    []byte
+	stream  string
+	time    time.Time
+	partial bool // the line continues in the next entry
+}
+
+// unwrapContainer recognizes the Docker json-file and Kubernetes CRI log
+// formats and returns the line the application wrote.
+func (s *Scanner) unwrapContainer(line []byte) (containerLine, bool) {
+	if bytes.HasPrefix(line, dockerPrefix) {
+		return unwrapDocker(line, &s.dec)
+	}
+	return unwrapCRI(line)
+}
+
+// unwrapDocker reads a json-file entry such as
+// {"log":"text\n","stream":"stderr","time":"..."}. Docker splits long lines
+// across entries, leaving the newline off all but the last.
+func unwrapDocker(line []byte, d *decoder) (containerLine, bool) {
+	var c containerLine
+	d.reset(line)
+	entry, err := d.object()
+	if err != nil || d.peek() != 0 {
+		return c, false
+	}
+
+	hasLog := false
+	for _, a := range entry {
+		var str string
+		switch a.Key {
+		case "log":
+			str, hasLog = a.Value.(string)
+			c.text = []byte(str)
+		case "stream":
+			c.stream, _ = a.Value.(string)
+		case "time":
+			if str, _ = a.Value.(string); str != "" {
+				c.time, _ = time.Parse(time.RFC3339Nano, str)
+			}
+		case "attrs":
+		default:
+			return c, false
+		}
+	}
+	if !hasLog {
+		return c, false
+	}
+
+	if n := len(c.text); n > 0 && c.text[n-1] == '\n' {
+		c.text = c.text[:n-1]
+	} else {
+		c.partial = true
+	}
+	return c, true
+}
+
+// unwrapCRI reads a CRI entry such as "2026-02-26T10:15:30.123Z stderr F text",
+// where the tag is P for a partial line and F for the final part.
+func unwrapCRI(line []byte) (containerLine, bool) {
+	var c containerLine
+	if len(line) == 0 || line[0] < '0' || line[0] > '9' {
+		return c, false
+	}
+
+	ts, rest, ok := bytes.Cut(line, []byte(" "))
+	if !ok {
+		return c, false
+	}
+	stream, rest, ok := bytes.Cut(rest, []byte(" "))
+	if !ok {
+		return c, false
+	}
+	switch string(stream) {
+	case streamStdout, streamStderr:
+		c.stream = string(stream)
+	default:
+		return c, false
+	}
+	tags, text, _ := bytes.Cut(rest, []byte(" "))
+	tag, _, _ := bytes.Cut(tags, []byte(":"))
+	switch string(tag) {
+	case "F":
+	case "P":
+		c.partial = true
+	default:
+		return c, false
+	}
+
+	t, err := time.Parse(time.RFC3339Nano, string(ts))
+	if err != nil {
+		return c, false
+	}
+	c.time, c.text = t, text
+	return c, true
+}
+
+// feedContainer processes the line carried by a container log entry once
+// its partial entries have been joined. Each stream is joined on its own,
+// since runtimes interleave the entries of stdout and stderr.
+func (s *Scanner) feedContainer(w io.Writer, c containerLine) error {
+	j := &s.joined[streamIndex(c.stream)]
+	if !j.partial {
+		j.stream, j.time = c.stream, c.time
+	}
+	j.text = append(j.text, c.text...)
+	j.partial = c.partial
+	if j.partial && len(j.text) <= s.maxLineSize() {
+		return nil
+	}
+	return s.finishJoined(w, j)
+}
+
+// finishJoined processes the line joined in j.
+func (s *Scanner) finishJoined(w io.Writer, j *containerLine) error {
+	text := j.text
+	j.text, j.partial = j.text[:0], false
+
+	if len(text) > s.maxLineSize() {
+		return s.processOversized(w, text, len(text))
+	}
+	rec := &s.rec
+	if err := s.parse(text, rec); err != nil {
+		return s.passThrough(w, text, err)
+	}
+	rec.Stream = j.stream
+	if rec.Time.IsZero() {
+		rec.Time = j.time
+	}
+	return s.emit(w, rec)
+}
+
+// flushJoined processes partial container lines left unfinished.
+func (s *Scanner) flushJoined(w io.Writer) error {
+	for i := range s.joined {
+		if !s.joined[i].partial {
+			continue
+		}
+		if err := s.finishJoined(w, &s.joined[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// streamIndex returns the index of the join buffer for stream.
+func streamIndex(stream string) int {
+	if stream == streamStderr {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,464 @@
+package spretty
+
+import (
+	"encoding/json"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// maxNesting bounds how deeply objects and arrays may nest, matching
+// encoding/json's limit.
+const maxNesting = 10000
+
+// decoder is a single-pass JSON decoder over an in-memory line. Values are
+// decoded straight into the types Attr uses (string, json.Number, bool, nil,
+// []any and Group) without intermediate buffers.
+type decoder struct {
+	data  []byte
+	pos   int
+	depth int
+	buf   []byte // scratch space for unescaping strings
+
+	// text is data as a string, made the first time a string is decoded so
+	// that every string without escapes, and every number, shares that one
+	// allocation. rawStart is where the last string decoded starts in data,
+	// or -1 if it was unescaped into buf.
+	text     string
+	rawStart int
+
+	// keys interns object keys across lines when non-nil, since the same
+	// keys appear on nearly every line of a log stream.
+	keys map[string]string
+}
+
+// maxInternedKeys bounds the key intern table of a reused decoder.
+const maxInternedKeys = 1024
+
+func (d *decoder) reset(data []byte) {
+	d.data = data
+	d.pos = 0
+	d.depth = 0
+	d.text = ""
+}
+
+// slice returns data[start:end] as a string, without a copy of its own.
+func (d *decoder) slice(start, end int) string {
+	if d.text == "" {
+		d.text = string(d.data)
+	}
+	return d.text[start:end]
+}
+
+// str returns b, the string last decoded, as a string.
+func (d *decoder) str(b []byte) string {
+	if d.rawStart < 0 {
+		return string(b)
+	}
+	return d.slice(d.rawStart, d.rawStart+len(b))
+}
+
+// intern returns b, the string last decoded, as a string, reusing an earlier
+// copy when keys are interned.
+func (d *decoder) intern(b []byte) string {
+	if d.keys == nil {
+		return d.str(b)
+	}
+	if s, ok := d.keys[string(b)]; ok {
+		return s
+	}
+	s := string(b)
+	if len(d.keys) < maxInternedKeys {
+		d.keys[s] = s
+	}
+	return s
+}
+
+// decodeJSONString decodes s if it holds a JSON object or array.
+func decodeJSONString(s string) (any, bool) {
+	t := strings.TrimSpace(s)
+	if t == "" || (t[0] != '{' && t[0] != '[') {
+		return nil, false
+	}
+	var d decoder
+	d.reset([]byte(t))
+	v, err := d.value()
+	if err != nil || d.peek() != 0 {
+		return nil, false
+	}
+	return v, true
+}
+
+func (d *decoder) errorf(format string, args ...any) error {
+	return parseErrorf(d.pos, format, args...)
+}
+
+func (d *decoder) skipSpace() {
+	for d.pos < len(d.data) {
+		switch d.data[d.pos] {
+		case ' ', '\t', '\r', '\n':
+			d.pos++
+		default:
+			return
+		}
+	}
+}
+
+// peek returns the next non-space byte without consuming it, or 0 at the end.
+func (d *decoder) peek() byte {
+	d.skipSpace()
+	if d.pos >= len(d.data) {
+		return 0
+	}
+	return d.data[d.pos]
+}
+
+func (d *decoder) expect(c byte) error {
+	if d.peek() != c {
+		if d.pos >= len(d.data) {
+			return d.errorf("unexpected end of input, expected %q", c)
+		}
+		return d.errorf("unexpected %q, expected %q", d.data[d.pos], c)
+	}
+	d.pos++
+	return nil
+}
+
+// more advances past a separator inside an object or array. It reports false
+// once the closing delimiter end has been consumed.
+func (d *decoder) more(end byte) (bool, error) {
+	switch d.peek() {
+	case ',':
+		d.pos++
+		return true, nil
+	case end:
+		d.pos++
+		return false, nil
+	case 0:
+		return false, d.errorf("unexpected end of input, expected ',' or %q", end)
+	default:
+		return false, d.errorf("unexpected %q, expected ',' or %q", d.data[d.pos], end)
+	}
+}
+
+func (d *decoder) value() (any, error) {
+	switch c := d.peek(); c {
+	case '{':
+		return d.object()
+	case '[':
+		return d.array()
+	case '"':
+		return d.stringValue()
+	case 't':
+		return true, d.literal("true")
+	case 'f':
+		return false, d.literal("false")
+	case 'n':
+		return nil, d.literal("null")
+	case 0:
+		return nil, d.errorf("unexpected end of input, expected value")
+	default:
+		if c == '-' || (c >= '0' && c <= '9') {
+			return d.number()
+		}
+		return nil, d.errorf("unexpected %q, expected value", c)
+	}
+}
+
+func (d *decoder) nest() error {
+	d.depth++
+	if d.depth > maxNesting {
+		return d.errorf("exceeded max nesting depth")
+	}
+	return nil
+}
+
+func (d *decoder) object() (Group, error) {
+	if err := d.expect('{'); err != nil {
+		return nil, err
+	}
+	if err := d.nest(); err != nil {
+		return nil, err
+	}
+	defer func() { d.depth-- }()
+
+	g := Group{}
+	if d.peek() == '}' {
+		d.pos++
+		return g, nil
+	}
+	for {
+		key, err := d.key()
+		if err != nil {
+			return nil, err
+		}
+		val, err := d.value()
+		if err != nil {
+			return nil, err
+		}
+		g = append(g, Attr{Key: key, Value: val})
+
+		more, err := d.more('}')
+		if err != nil {
+			return nil, err
+		}
+		if !more {
+			return g, nil
+		}
+	}
+}
+
+// key decodes an object key and the colon that follows it.
+func (d *decoder) key() (string, error) {
+	b, err := d.keyBytes()
+	if err != nil {
+		return "", err
+	}
+	return d.intern(b), nil
+}
+
+// keyBytes is like key but returns the key without copying it. The result is
+// only valid until the next string is decoded.
+func (d *decoder) keyBytes() ([]byte, error) {
+	if d.peek() != '"' {
+		if d.pos >= len(d.data) {
+			return nil, d.errorf("unexpected end of input, expected object key")
+		}
+		return nil, d.errorf("unexpected %q, expected object key", d.data[d.pos])
+	}
+	b, err := d.stringBytes()
+	if err != nil {
+		return nil, err
+	}
+	if err := d.expect(':'); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func (d *decoder) array() ([]any, error) {
+	if err := d.expect('['); err != nil {
+		return nil, err
+	}
+	if err := d.nest(); err != nil {
+		return nil, err
+	}
+	defer func() { d.depth-- }()
+
+	arr := []any{}
+	if d.peek() == ']' {
+		d.pos++
+		return arr, nil
+	}
+	for {
+		val, err := d.value()
+		if err != nil {
+			return nil, err
+		}
+		arr = append(arr, val)
+
+		more, err := d.more(']')
+		if err != nil {
+			return nil, err
+		}
+		if !more {
+			return arr, nil
+		}
+	}
+}
+
+func (d *decoder) literal(lit string) error {
+	end := d.pos + len(lit)
+	if end > len(d.data) && strings.HasPrefix(lit, string(d.data[d.pos:])) {
+		d.pos = len(d.data)
+		return d.errorf("unexpected end of input in literal")
+	}
+	if end > len(d.data) || string(d.data[d.pos:end]) != lit {
+		return d.errorf("invalid literal, expected %s", lit)
+	}
+	d.pos = end
+	return nil
+}
+
+func (d *decoder) number() (json.Number, error) {
+	start := d.pos
+	data := d.data
+
+	if d.pos < len(data) && data[d.pos] == '-' {
+		d.pos++
+	}
+	switch {
+	case d.pos < len(data) && data[d.pos] == '0':
+		d.pos++
+	case d.pos < len(data) && data[d.pos] >= '1' && data[d.pos] <= '9':
+		d.skipDigits()
+	default:
+		return "", d.errorf("invalid number")
+	}
+	if d.pos < len(data) && data[d.pos] == '.' {
+		d.pos++
+		if d.skipDigits() == 0 {
+			return "", d.errorf("invalid number fraction")
+		}
+	}
+	if d.pos < len(data) && (data[d.pos] == 'e' || data[d.pos] == 'E') {
+		d.pos++
+		if d.pos < len(data) && (data[d.pos] == '+' || data[d.pos] == '-') {
+			d.pos++
+		}
+		if d.skipDigits() == 0 {
+			return "", d.errorf("invalid number exponent")
+		}
+	}
+	return json.Number(d.slice(start, d.pos)), nil
+}
+
+func (d *decoder) skipDigits() int {
+	start := d.pos
+	for d.pos < len(d.data) && d.data[d.pos] >= '0' && d.data[d.pos] <= '9' {
+		d.pos++
+	}
+	return d.pos - start
+}
+
+// stringValue decodes a JSON string.
+func (d *decoder) stringValue() (string, error) {
+	b, err := d.stringBytes()
+	if err != nil {
+		return "", err
+	}
+	return d.str(b), nil
+}
+
+// stringBytes decodes a JSON string without copying it. Strings without
+// escapes or invalid UTF-8 are returned as a slice of the input; anything
+// else goes through the scratch buffer. The result is only valid until the
+// next string is decoded.
+func (d *decoder) stringBytes() ([]byte, error) {
+	d.pos++ // opening quote
+	start := d.pos
+	for d.pos < len(d.data) {
+		c := d.data[d.pos]
+		switch {
+		case c == '"':
+			d.pos++
+			d.rawStart = start
+			return d.data[start : d.pos-1], nil
+		case c == '\\':
+			return d.unescape(start)
+		case c < 0x20:
+			return nil, d.errorf("invalid control character in string")
+		case c < utf8.RuneSelf:
+			d.pos++
+		default:
+			r, size := utf8.DecodeRune(d.data[d.pos:])
+			if r == utf8.RuneError && size == 1 {
+				return d.unescape(start)
+			}
+			d.pos += size
+		}
+	}
+	return nil, d.errorf("unexpected end of input in string")
+}
+
+// unescape finishes decoding a string into the scratch buffer, resolving
+// escapes and replacing invalid UTF-8 with U+FFFD like encoding/json.
+func (d *decoder) unescape(start int) ([]byte, error) {
+	d.rawStart = -1
+	d.buf = append(d.buf[:0], d.data[start:d.pos]...)
+	for d.pos < len(d.data) {
+		c := d.data[d.pos]
+		switch {
+		case c == '"':
+			d.pos++
+			return d.buf, nil
+		case c == '\\':
+			if err := d.escape(); err != nil {
+				return nil, err
+			}
+		case c < 0x20:
+			return nil, d.errorf("invalid control character in string")
+		case c < utf8.RuneSelf:
+			d.buf = append(d.buf, c)
+			d.pos++
+		default:
+			r, size := utf8.DecodeRune(d.data[d.pos:])
+			d.buf = utf8.AppendRune(d.buf, r)
+			d.pos += size
+		}
+	}
+	return nil, d.errorf("unexpected end of input in string")
+}
+
+func (d *decoder) escape() error {
+	if d.pos+1 >= len(d.data) {
+		return d.errorf("unexpected end of input in escape")
+	}
+	c := d.data[d.pos+1]
+	d.pos += 2
+	switch c {
+	case '"', '\\', '/':
+		d.buf = append(d.buf, c)
+	case 'b':
+		d.buf = append(d.buf, '\b')
+	case 'f':
+		d.buf = append(d.buf, '\f')
+	case 'n':
+		d.buf = append(d.buf, '\n')
+	case 'r':
+		d.buf = append(d.buf, '\r')
+	case 't':
+		d.buf = append(d.buf, '\t')
+	case 'u':
+		r, ok := d.hex4()
+		if !ok {
+			return d.errorf("invalid unicode escape")
+		}
+		if utf16.IsSurrogate(r) {
+			r = d.lowSurrogate(r)
+		}
+		d.buf = utf8.AppendRune(d.buf, r)
+	default:
+		d.pos--
+		return d.errorf("invalid escape %q", c)
+	}
+	return nil
+}
+
+// lowSurrogate combines high with a following \uXXXX low surrogate. A lone
+// surrogate decodes to U+FFFD.
+func (d *decoder) lowSurrogate(high rune) rune {
+	if d.pos+1 < len(d.data) && d.data[d.pos] == '\\' && d.data[d.pos+1] == 'u' {
+		save := d.pos
+		d.pos += 2
+		if low, ok := d.hex4(); ok {
+			if r := utf16.DecodeRune(high, low); r != utf8.RuneError {
+				return r
+			}
+		}
+		d.pos = save
+	}
+	return utf8.RuneError
+}
+
+func (d *decoder) hex4() (rune, bool) {
+	if d.pos+4 > len(d.data) {
+		return 0, false
+	}
+	var r rune
+	for _, c := range d.data[d.pos : d.pos+4] {
+		switch {
+		case c >= '0' && c <= '9':
+			c -= '0'
+		case c >= 'a' && c <= 'f':
+			c = c - 'a' + 10
+		case c >= 'A' && c <= 'F':
+			c = c - 'A' + 10
+		default:
+			return 0, false
+		}
+		r = r<<4 | rune(c)
+	}
+	d.pos += 4
+	return r, true
+}
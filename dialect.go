@@ -0,0 +1,120 @@
+package spretty
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Format identifies the logger dialect a Parser reads.
+type Format int
+
+const (
+	// FormatAuto recognizes slog, zap, zerolog and logrus lines per line.
+	FormatAuto Format = iota
+	// FormatSlog reads log/slog JSONHandler output.
+	FormatSlog
+	// FormatZap reads go.uber.org/zap JSON output.
+	FormatZap
+	// FormatZerolog reads github.com/rs/zerolog output.
+	FormatZerolog
+	// FormatLogrus reads github.com/sirupsen/logrus JSONFormatter output.
+	FormatLogrus
+)
+
+var formatNames = [...]string{ //nolint:gochecknoglobals // constant lookup table
+	FormatAuto:    "auto",
+	FormatSlog:    "slog",
+	FormatZap:     "zap",
+	FormatZerolog: "zerolog",
+	FormatLogrus:  "logrus",
+}
+
+// String returns the lowercase name of the format.
+func (f Format) String() string {
+	if f < 0 || int(f) >= len(formatNames) {
+		return fmt.Sprintf("Format(%d)", int(f))
+	}
+	return formatNames[f]
+}
+
+// ParseFormat returns the Format with the given name.
+func ParseFormat(name string) (Format, error) {
+	for i, n := range formatNames {
+		if strings.EqualFold(name, n) {
+			return Format(i), nil
+		}
+	}
+	return FormatAuto, fmt.Errorf("unknown format: %q", name)
+}
+
+// field identifies a core record field.
+type field int
+
+const (
+	fieldTime field = iota
+	fieldLevel
+	fieldMessage
+	fieldSource
+	fieldFunction
+	numFields
+)
+
+// dialect lists the candidate keys of each core field in priority order.
+type dialect [numFields][]string
+
+func newDialect(f Format, k keys) dialect {
+	switch f {
+	case FormatSlog:
+		return dialect{
+			fieldTime:    {k.time},
+			fieldLevel:   {k.level},
+			fieldMessage: {k.message},
+			fieldSource:  {k.source},
+		}
+	case FormatZap:
+		return dialect{
+			fieldTime:    {"ts"},
+			fieldLevel:   {"level"},
+			fieldMessage: {"msg"},
+			fieldSource:  {"caller"},
+		}
+	case FormatZerolog:
+		return dialect{
+			fieldTime:    {"time"},
+			fieldLevel:   {"level"},
+			fieldMessage: {"message"},
+			fieldSource:  {"caller"},
+		}
+	case FormatLogrus:
+		return dialect{
+			fieldTime:     {"time"},
+			fieldLevel:    {"level"},
+			fieldMessage:  {"msg"},
+			fieldSource:   {"file"},
+			fieldFunction: {"func"},
+		}
+	case FormatAuto:
+		fallthrough
+	default:
+		return dialect{
+			fieldTime:    {k.time, "ts"},
+			fieldLevel:   {k.level},
+			fieldMessage: {k.message, "message"},
+			fieldSource:  {k.source, "caller"},
+		}
+	}
+}
+
+// lookupKey reports which field key belongs to, the dialect's copy of the
+// key, and its rank among the field's candidates (0 is the preferred key).
+// Accepting []byte lets the parser look keys up without allocating.
+func lookupKey[K string | []byte](d *dialect, key K) (field, string, int, bool) {
+	for f, candidates := range d {
+		for rank, c := range candidates {
+			if c == string(key) {
+				return field(f), c, rank, true
+			}
+		}
+	}
+	return 0, "", 0, false
+}
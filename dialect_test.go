@@ -0,0 +1,43 @@
+package spretty_test
+
+import (
+	"testing"
+
+	spretty "github.com/mickamy/slog-pretty"
+)
+
+func TestParseFormat(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		input   string
+		want    spretty.Format
+		wantErr bool
+	}{
+		{name: "auto", input: "auto", want: spretty.FormatAuto},
+		{name: "slog", input: "slog", want: spretty.FormatSlog},
+		{name: "zap", input: "zap", want: spretty.FormatZap},
+		{name: "zerolog", input: "zerolog", want: spretty.FormatZerolog},
+		{name: "logrus upper case", input: "LOGRUS", want: spretty.FormatLogrus},
+		{name: "unknown", input: "log4j", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_ = t.Context()
+
+			got, err := spretty.ParseFormat(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseFormat(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("ParseFormat(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+			if !tt.wantErr && got.String() != tt.want.String() {
+				t.Errorf("String() = %q, want %q", got.String(), tt.want.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package spretty
+
+import "strings"
+
+// parseEnvelope parses a JSON line whose record sits at the configured
+// envelope path.
+func (p *Parser) parseEnvelope(line []byte, start int, rec *Record, d *decoder) error {
+	d.reset(line)
+	d.pos = start
+	outer, err := d.object()
+	if err != nil {
+		return err
+	}
+	if d.peek() != 0 {
+		return d.errorf("unexpected %q after JSON object", d.data[d.pos])
+	}
+
+	inner, rest, ok := unwrapEnvelope(outer, p.cfg.envelope)
+	if !ok {
+		return p.parseGroup(outer, rec)
+	}
+	path := strings.Join(p.cfg.envelope, ".")
+	if s, isStr := inner.(string); isStr {
+		// The record may have been logged as an escaped JSON string.
+		var sd decoder
+		sd.reset([]byte(s))
+		if inner, err = sd.object(); err != nil || sd.peek() != 0 {
+			return parseErrorf(-1, "%s does not hold a JSON object", path)
+		}
+	}
+	g, isGroup := inner.(Group)
+	if !isGroup {
+		return parseErrorf(-1, "%s is %s, not an object", path, jsonType(inner))
+	}
+
+	if err := p.parseGroup(g, rec); err != nil {
+		return err
+	}
+	if p.cfg.keepEnvelope {
+		rec.Attrs = append(rec.Attrs, rest...)
+	}
+	return nil
+}
+
+// unwrapEnvelope returns the value at path in g, and g's other fields with
+// the path removed. Groups left empty along the path are dropped.
+func unwrapEnvelope(g Group, path []string) (any, Group, bool) {
+	for i, a := range g {
+		if a.Key != path[0] {
+			continue
+		}
+		rest := make(Group, 0, len(g)-1)
+		rest = append(rest, g[:i]...)
+		if len(path) == 1 {
+			return a.Value, append(rest, g[i+1:]...), true
+		}
+
+		sub, ok := a.Value.(Group)
+		if !ok {
+			return nil, nil, false
+		}
+		v, subRest, ok := unwrapEnvelope(sub, path[1:])
+		if !ok {
+			return nil, nil, false
+		}
+		if len(subRest) > 0 {
+			rest = append(rest, Attr{Key: a.Key, Value: subRest})
+		}
+		return v, append(rest, g[i+1:]...), true
+	}
+	return nil, nil, false
+}
+
+// parseGroup reads a record from a decoded object.
+func (p *Parser) parseGroup(g Group, rec *Record) error {
+	var slots fieldSlots
+	for _, a := range g {
+		if f, key, rank, core := lookupKey(&p.dialect, a.Key); core {
+			sl := slot{key: key, val: a.Value, off: -1}
+			if s, ok := a.Value.(string); ok {
+				sl = slot{key: key, str: s, isStr: true, off: -1}
+			}
+			if p.claim(rec, &slots, f, rank, sl) {
+				continue
+			}
+		}
+		rec.Attrs = append(rec.Attrs, a)
+	}
+	return p.finishRecord(rec, &slots)
+}
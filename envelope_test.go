@@ -0,0 +1,113 @@
+package spretty_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	spretty "github.com/mickamy/slog-pretty"
+)
+
+func TestParse_Envelope(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		opts   []spretty.Option
+		input  string
+		wantOK bool
+		check  func(t *testing.T, r *spretty.Record)
+	}{
+		{
+			name: "record under top-level key",
+			opts: []spretty.Option{spretty.WithEnvelope("jsonPayload")},
+			input: `{"insertId":"x1","jsonPayload":{"time":"2026-02-26T10:15:30Z","level":"INFO","msg":"hi","n":1},` +
+				`"severity":"INFO"}`,
+			wantOK: true,
+			check: func(t *testing.T, r *spretty.Record) {
+				t.Helper()
+				if r.Level != "INFO" || r.Message != "hi" || r.Time.IsZero() {
+					t.Errorf("record = %+v", r)
+				}
+				want := []spretty.Attr{{Key: "n", Value: json.Number("1")}}
+				if !reflect.DeepEqual(r.Attrs, want) {
+					t.Errorf("Attrs = %v, want %v", r.Attrs, want)
+				}
+			},
+		},
+		{
+			name: "nested path keeping the envelope",
+			opts: []spretty.Option{spretty.WithEnvelope("data.record"), spretty.WithKeepEnvelope()},
+			input: `{"host":"a","data":{"record":{"level":"WARN","msg":"slow","ms":9},"shard":2},` +
+				`"wrap":{"record":1}}`,
+			wantOK: true,
+			check: func(t *testing.T, r *spretty.Record) {
+				t.Helper()
+				if r.Level != "WARN" || r.Message != "slow" {
+					t.Errorf("record = %+v", r)
+				}
+				want := []spretty.Attr{
+					{Key: "ms", Value: json.Number("9")},
+					{Key: "host", Value: "a"},
+					{Key: "data", Value: spretty.Group{{Key: "shard", Value: json.Number("2")}}},
+					{Key: "wrap", Value: spretty.Group{{Key: "record", Value: json.Number("1")}}},
+				}
+				if !reflect.DeepEqual(r.Attrs, want) {
+					t.Errorf("Attrs = %v, want %v", r.Attrs, want)
+				}
+			},
+		},
+		{
+			name:   "record logged as a JSON string",
+			opts:   []spretty.Option{spretty.WithEnvelope("body")},
+			input:  `{"body":"{\"level\":\"ERROR\",\"msg\":\"boom\"}","resource":"svc"}`,
+			wantOK: true,
+			check: func(t *testing.T, r *spretty.Record) {
+				t.Helper()
+				if r.Level != "ERROR" || r.Message != "boom" || len(r.Attrs) != 0 {
+					t.Errorf("record = %+v", r)
+				}
+			},
+		},
+		{
+			name:   "line without the path is read as is",
+			opts:   []spretty.Option{spretty.WithEnvelope("_source")},
+			input:  `{"level":"INFO","msg":"direct"}`,
+			wantOK: true,
+			check:  checkLevel("INFO"),
+		},
+		{
+			name:   "non-object at the path",
+			opts:   []spretty.Option{spretty.WithEnvelope("fields")},
+			input:  `{"fields":42,"level":"INFO","msg":"hi"}`,
+			wantOK: false,
+		},
+		{
+			name:   "string at the path that is not JSON",
+			opts:   []spretty.Option{spretty.WithEnvelope("body")},
+			input:  `{"body":"plain text"}`,
+			wantOK: false,
+		},
+		{
+			name:   "logfmt lines are unaffected",
+			opts:   []spretty.Option{spretty.WithEnvelope("body")},
+			input:  `level=INFO msg=hi`,
+			wantOK: true,
+			check:  checkLevel("INFO"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			rec, err := spretty.NewParser(tt.opts...).Parse([]byte(tt.input))
+			if ok := err == nil; ok != tt.wantOK {
+				t.Fatalf("Parse() error = %v, wantOK %v", err, tt.wantOK)
+			}
+			if tt.check != nil && rec != nil {
+				tt.check(t, rec)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package spretty
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ErrNotRecord is matched by every error [ParseInto] returns for lines that
+// are not log records.
+var ErrNotRecord = errors.New("spretty: not a log record")
+
+// ParseError reports why a line is not a log record.
+type ParseError struct {
+	// Offset is the byte offset in the line where parsing stopped, or -1 if
+	// the problem is not at a particular position.
+	Offset int
+
+	// Reason describes the problem, e.g. "unexpected '}', expected value".
+	Reason string
+}
+
+func (e *ParseError) Error() string {
+	if e.Offset < 0 {
+		return fmt.Sprintf("%v: %s", ErrNotRecord, e.Reason)
+	}
+	return fmt.Sprintf("%v: %s at byte %d", ErrNotRecord, e.Reason, e.Offset)
+}
+
+// Is reports whether target is [ErrNotRecord].
+func (e *ParseError) Is(target error) bool {
+	return target == ErrNotRecord
+}
+
+func parseErrorf(offset int, format string, args ...any) *ParseError {
+	return &ParseError{Offset: offset, Reason: fmt.Sprintf(format, args...)}
+}
+
+// jsonType names the JSON type of a decoded value for error messages.
+func jsonType(v any) string {
+	switch v.(type) {
+	case Group, map[string]any:
+		return "an object"
+	case []any:
+		return "an array"
+	case string:
+		return "a string"
+	case json.Number:
+		return "a number"
+	case bool:
+		return "a boolean"
+	case nil:
+		return "null"
+	default:
+		return fmt.Sprintf("%T", v)
+	}
+}
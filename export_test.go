@@ -1,6 +0,0 @@
-package spretty
-
-var (
-	LevelColor = levelColor
-	Colorize   = colorize
-)
@@ -0,0 +1,162 @@
+package spretty
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// followInterval is how often a followed file is checked for new data.
+const followInterval = 200 * time.Millisecond
+
+// Follow opens the file at path for reading like tail -F. Reading starts at
+// the last n records of the file, where a record is a line together with
+// the lines below it that start with whitespace or a closing bracket, such as
+// the rest of a pretty-printed JSON object or a stack trace. At the end of
+// the file, Read waits for more data instead of returning io.EOF. When the
+// file is rotated, Read continues with the new file at path, and when it is
+// truncated, from its start. Once ctx is done, Read returns io.EOF, which
+// ends a [Scanner.Scan] over the reader.
+func Follow(ctx context.Context, path string, n int) (io.ReadCloser, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening followed file: %w", err)
+	}
+	off, err := tailOffset(f, n)
+	if err == nil {
+		_, err = f.Seek(off, io.SeekStart)
+	}
+	if err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("seeking to last records: %w", err)
+	}
+	return &follower{ctx: ctx, path: path, f: f, off: off}, nil
+}
+
+// follower is the reader returned by Follow.
+type follower struct {
+	ctx  context.Context //nolint:containedctx // Read has no context of its own
+	path string
+	f    *os.File
+	off  int64
+}
+
+func (r *follower) Read(p []byte) (int, error) {
+	for {
+		n, err := r.f.Read(p)
+		r.off += int64(n)
+		if n > 0 {
+			return n, nil
+		}
+		if err != nil && !errors.Is(err, io.EOF) {
+			return 0, fmt.Errorf("reading followed file: %w", err)
+		}
+
+		// At the end of the file: switch files if it was rotated, or
+		// start over if it was truncated, then wait for more data.
+		reopened, err := r.reopen()
+		if err != nil {
+			return 0, err
+		}
+		if reopened {
+			continue
+		}
+		select {
+		case <-r.ctx.Done():
+			return 0, io.EOF
+		case <-time.After(followInterval):
+		}
+	}
+}
+
+// reopen checks whether the file at path was replaced or truncated and
+// prepares to read it from the start if so.
+func (r *follower) reopen() (bool, error) {
+	fi, err := os.Stat(r.path)
+	if err != nil {
+		// Rotated away and not recreated yet; keep waiting.
+		return false, nil //nolint:nilerr // a missing file is expected during rotation
+	}
+	cur, err := r.f.Stat()
+	if err != nil {
+		return false, fmt.Errorf("checking followed file: %w", err)
+	}
+
+	switch {
+	case !os.SameFile(fi, cur):
+		f, err := os.Open(r.path)
+		if err != nil {
+			return false, nil //nolint:nilerr // retried on the next poll
+		}
+		_ = r.f.Close()
+		r.f, r.off = f, 0
+		return true, nil
+	case fi.Size() < r.off:
+		if _, err := r.f.Seek(0, io.SeekStart); err != nil {
+			return false, fmt.Errorf("rewinding truncated file: %w", err)
+		}
+		r.off = 0
+		return true, nil
+	default:
+		return false, nil
+	}
+}
+
+func (r *follower) Close() error {
+	if err := r.f.Close(); err != nil {
+		return fmt.Errorf("closing followed file: %w", err)
+	}
+	return nil
+}
+
+// tailOffset returns the offset of the start of the last n records of f,
+// stepping back over lines that continue the record above them.
+func tailOffset(f *os.File, n int) (int64, error) {
+	fi, err := f.Stat()
+	if err != nil {
+		return 0, fmt.Errorf("checking file size: %w", err)
+	}
+	end := fi.Size()
+	if n <= 0 {
+		return end, nil
+	}
+
+	buf := make([]byte, 32*1024)
+	pos := end
+	next := byte(0) // the byte after the one being looked at
+	for pos > 0 {
+		size := min(int64(len(buf)), pos)
+		pos -= size
+		chunk := buf[:size]
+		if _, err := f.ReadAt(chunk, pos); err != nil && !errors.Is(err, io.EOF) {
+			return 0, fmt.Errorf("reading file tail: %w", err)
+		}
+		for i := len(chunk) - 1; i >= 0; i-- {
+			c := next
+			next = chunk[i]
+			if chunk[i] != '\n' || pos+int64(i) == end-1 || !startsRecord(c) {
+				// Neither the newline ending the file nor one followed by
+				// a continuation line starts a record.
+				continue
+			}
+			if n--; n == 0 {
+				return pos + int64(i) + 1, nil
+			}
+		}
+	}
+	return 0, nil
+}
+
+// startsRecord reports whether a line beginning with c starts a record rather
+// than continuing the one above it.
+func startsRecord(c byte) bool {
+	switch c {
+	case ' ', '\t', '\r', '\n', '}', ']', ',':
+		return false
+	default:
+		return true
+	}
+}
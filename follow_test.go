@@ -0,0 +1,154 @@
+package spretty_test
+
+import (
+	"bufio"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	spretty "github.com/mickamy/slog-pretty"
+)
+
+func TestFollow(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "app.log")
+	writeFile(t, path, "one\ntwo\nthree\n", os.O_CREATE|os.O_WRONLY)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+
+	r, err := spretty.Follow(ctx, path, 2)
+	if err != nil {
+		t.Fatalf("Follow() error = %v", err)
+	}
+	defer func() { _ = r.Close() }()
+
+	lines := make(chan string)
+	done := make(chan error, 1)
+	go func() {
+		sc := bufio.NewScanner(r)
+		for sc.Scan() {
+			lines <- sc.Text()
+		}
+		done <- sc.Err()
+	}()
+
+	expect := func(want string) {
+		t.Helper()
+		select {
+		case got := <-lines:
+			if got != want {
+				t.Fatalf("line = %q, want %q", got, want)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for %q", want)
+		}
+	}
+
+	expect("two")
+	expect("three")
+
+	writeFile(t, path, "four\n", os.O_APPEND|os.O_WRONLY)
+	expect("four")
+
+	// Truncation starts over from the beginning of the file.
+	writeFile(t, path, "", os.O_TRUNC|os.O_WRONLY)
+	time.Sleep(500 * time.Millisecond)
+	writeFile(t, path, "five\n", os.O_APPEND|os.O_WRONLY)
+	expect("five")
+
+	// Rotation switches to the new file at path.
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, path, "six\n", os.O_CREATE|os.O_WRONLY)
+	expect("six")
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("reading after cancel: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Read did not return after cancel")
+	}
+}
+
+func TestFollow_Records(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		content string
+		records int
+		want    string
+	}{
+		{name: "more lines than asked", content: "a\nb\nc\n", records: 2, want: "b\nc\n"},
+		{name: "fewer lines than asked", content: "a\nb\n", records: 5, want: "a\nb\n"},
+		{name: "no trailing newline", content: "a\nb\nc", records: 1, want: "c"},
+		{name: "zero records", content: "a\nb\n", records: 0, want: ""},
+		{name: "empty file", content: "", records: 3, want: ""},
+		{
+			name:    "pretty-printed object counts as one record",
+			content: "a\n{\n  \"level\": \"INFO\",\n  \"req\": {\n    \"id\": 1\n  }\n}\nb\n",
+			records: 2,
+			want:    "{\n  \"level\": \"INFO\",\n  \"req\": {\n    \"id\": 1\n  }\n}\nb\n",
+		},
+		{
+			name:    "indented stack trace stays with its line",
+			content: "a\npanic: boom\n\tmain.go:1\n\tmain.go:2\n",
+			records: 1,
+			want:    "panic: boom\n\tmain.go:1\n\tmain.go:2\n",
+		},
+		{name: "blank lines are not records", content: "a\n\n\nb\n", records: 2, want: "a\n\n\nb\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			path := filepath.Join(t.TempDir(), "app.log")
+			writeFile(t, path, tt.content, os.O_CREATE|os.O_WRONLY)
+
+			ctx, cancel := context.WithCancel(t.Context())
+			cancel() // read what is there, then stop
+
+			r, err := spretty.Follow(ctx, path, tt.records)
+			if err != nil {
+				t.Fatalf("Follow() error = %v", err)
+			}
+			defer func() { _ = r.Close() }()
+
+			var got []byte
+			buf := make([]byte, 64)
+			for {
+				n, err := r.Read(buf)
+				got = append(got, buf[:n]...)
+				if err != nil {
+					break
+				}
+			}
+			if string(got) != tt.want {
+				t.Errorf("read %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func writeFile(t *testing.T, path, data string, flag int) {
+	t.Helper()
+	f, err := os.OpenFile(path, flag, 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteString(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
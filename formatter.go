@@ -1,10 +1,13 @@
 package spretty
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"slices"
+	"strconv"
 	"strings"
+	"unicode/utf8"
 )
 
 // Formatter formats parsed Records into human-readable output.
@@ -19,93 +22,247 @@ func NewFormatter(opts ...Option) *Formatter {
 
 // Format returns the formatted representation of a Record.
 func (f *Formatter) Format(r *Record) string {
-	var b strings.Builder
+	var b bytes.Buffer
+	f.writeRecord(&b, r)
+	return b.String()
+}
+
+// writeRecord appends the formatted record to b without a trailing newline.
+func (f *Formatter) writeRecord(b *bytes.Buffer, r *Record) {
+	for _, l := range r.Labels {
+		f.writeLabel(b, l, f.cfg.theme.label(strings.TrimSpace(l)))
+	}
+	if r.Stream == streamStderr {
+		f.writeColored(b, streamStderr, f.cfg.theme.Stderr)
+		f.writeColored(b, " | ", f.cfg.theme.Separator)
+	}
 
 	if !r.Time.IsZero() {
-		b.WriteString(colorize(r.Time.Format(f.cfg.timeFormat), gray, f.cfg.noColor))
+		f.setColor(b, f.cfg.theme.Time)
+		b.Write(r.Time.AppendFormat(b.AvailableBuffer(), f.cfg.timeFormat))
+		f.resetColor(b, f.cfg.theme.Time)
 		b.WriteByte(' ')
 	}
 
 	if r.Level != "" {
-		padded := fmt.Sprintf("%-*s", f.cfg.levelWidth, r.Level)
-		b.WriteString(colorize(padded, levelColor(r.Level), f.cfg.noColor))
+		color := f.cfg.theme.level(r.Level)
+		f.setColor(b, color)
+		b.WriteString(r.Level)
+		for n := utf8.RuneCountInString(r.Level); n < f.cfg.levelWidth; n++ {
+			b.WriteByte(' ')
+		}
+		f.resetColor(b, color)
 		b.WriteByte(' ')
 	}
 
-	b.WriteString(colorize(r.Message, bold, f.cfg.noColor))
+	// Continuation lines of a multi-line message follow the source, or the
+	// message is quoted onto one line in compact mode.
+	msg, rest, multiline := strings.Cut(r.Message, "\n")
+	if multiline && f.cfg.compact {
+		msg, multiline = strconv.Quote(r.Message), false
+	}
+	f.writeColored(b, strings.TrimSuffix(msg, "\r"), f.cfg.theme.Message)
 
 	if r.Source != nil {
-		src := fmt.Sprintf("(%s %s:%d)", r.Source.Function, r.Source.File, r.Source.Line)
 		b.WriteByte(' ')
-		b.WriteString(colorize(src, dim, f.cfg.noColor))
+		f.setColor(b, f.cfg.theme.Source)
+		b.WriteByte('(')
+		if r.Source.Function != "" {
+			b.WriteString(r.Source.Function)
+			b.WriteByte(' ')
+		}
+		b.WriteString(r.Source.File)
+		b.WriteByte(':')
+		b.Write(strconv.AppendInt(b.AvailableBuffer(), int64(r.Source.Line), 10))
+		b.WriteByte(')')
+		f.resetColor(b, f.cfg.theme.Source)
 	}
 
-	attrs := f.filterAttrs(r.Attrs)
-	if len(attrs) > 0 {
-		b.WriteByte('\n')
-		f.writeAttrs(&b, attrs, f.cfg.indent)
+	if multiline {
+		f.writeBlock(b, rest, 1, f.cfg.theme.Message)
 	}
 
-	return b.String()
+	for _, a := range r.Attrs {
+		if _, ignored := f.cfg.ignoreKeys[a.Key]; ignored {
+			continue
+		}
+		if f.cfg.compact {
+			f.writeInlineAttr(b, a.Key, a.Value)
+			continue
+		}
+		f.writeAttr(b, a, 1)
+	}
 }
 
-func (f *Formatter) filterAttrs(attrs []Attr) []Attr {
-	if len(f.cfg.ignoreKeys) == 0 {
-		return attrs
-	}
-	filtered := make([]Attr, 0, len(attrs))
-	for _, a := range attrs {
-		if _, ignored := f.cfg.ignoreKeys[a.Key]; !ignored {
-			filtered = append(filtered, a)
+// writeLabel writes a label column in color.
+func (f *Formatter) writeLabel(b *bytes.Buffer, label string, color Color) {
+	f.writeColored(b, label, color)
+	f.writeColored(b, " | ", f.cfg.theme.Separator)
+}
+
+// writeAttr writes a on a new line indented depth times.
+func (f *Formatter) writeAttr(b *bytes.Buffer, a Attr, depth int) {
+	b.WriteByte('\n')
+	f.writeIndent(b, depth)
+	f.writeColored(b, a.Key, f.cfg.theme.Key)
+	f.writeColored(b, "=", f.cfg.theme.Equals)
+	f.writeValue(b, a.Value, depth)
+}
+
+// writeValue writes the value of an attr at depth.
+func (f *Formatter) writeValue(b *bytes.Buffer, v any, depth int) {
+	switch v := v.(type) {
+	case Group:
+		if len(v) == 0 {
+			b.WriteString("{}")
+			return
 		}
+		f.writeGroup(b, v, depth+1)
+	case map[string]any:
+		if len(v) == 0 {
+			b.WriteString("{}")
+			return
+		}
+		f.writeMap(b, v, depth+1)
+	case []any:
+		if s, ok := f.inlineArray(v); ok {
+			b.WriteString(s)
+			return
+		}
+		f.writeArray(b, v, depth+1)
+	case string:
+		if f.cfg.expandJSON {
+			if jv, ok := decodeJSONString(v); ok {
+				f.writeExpanded(b, jv, depth)
+				return
+			}
+		}
+		f.writeString(b, v, depth)
+	default:
+		f.writeColored(b, f.formatScalar(v), f.valueColor(v))
 	}
-	return filtered
 }
 
-func (f *Formatter) writeAttrs(b *strings.Builder, attrs []Attr, prefix string) {
-	for i, a := range attrs {
-		b.WriteString(prefix)
-		b.WriteString(colorize(a.Key, cyan, f.cfg.noColor))
-		b.WriteString(colorize("=", gray, f.cfg.noColor))
+// valueColor returns the theme's color for a scalar value of v's type.
+func (f *Formatter) valueColor(v any) Color {
+	switch v.(type) {
+	case string:
+		return f.cfg.theme.String
+	case json.Number, int, int64, uint64, float64:
+		return f.cfg.theme.Number
+	case bool:
+		return f.cfg.theme.Bool
+	case nil:
+		return f.cfg.theme.Null
+	default:
+		return ""
+	}
+}
+
+// writeString writes a string value. Multi-line strings, such as stack
+// traces, go in a block indented below the key so that they keep to the
+// record's layout.
+func (f *Formatter) writeString(b *bytes.Buffer, s string, depth int) {
+	if !strings.Contains(s, "\n") {
+		f.writeColored(b, s, f.cfg.theme.String)
+		return
+	}
+	f.writeBlock(b, s, depth+1, f.cfg.theme.String)
+}
 
-		switch v := a.Value.(type) {
-		case map[string]any:
-			b.WriteByte('\n')
-			f.writeMap(b, v, prefix+f.cfg.indent)
-		default:
-			b.WriteString(f.formatScalar(a.Value))
+// writeBlock writes each line of s on a new line indented depth times,
+// dropping trailing newlines.
+func (f *Formatter) writeBlock(b *bytes.Buffer, s string, depth int, color Color) {
+	for line := range strings.Lines(strings.TrimRight(s, "\r\n")) {
+		b.WriteByte('\n')
+		f.writeIndent(b, depth)
+		if line = strings.TrimRight(line, "\r\n"); line != "" {
+			f.writeColored(b, line, color)
 		}
+	}
+}
 
-		if i < len(attrs)-1 {
-			b.WriteByte('\n')
+func (f *Formatter) writeIndent(b *bytes.Buffer, depth int) {
+	for range depth {
+		b.WriteString(f.cfg.indent)
+	}
+}
+
+// writeExpanded writes a value decoded from a JSON string, marked as such.
+func (f *Formatter) writeExpanded(b *bytes.Buffer, v any, depth int) {
+	f.writeColored(b, "(json)", f.cfg.theme.Note)
+	if !f.expands(v) {
+		b.WriteByte(' ')
+	}
+	f.writeValue(b, v, depth)
+}
+
+// expands reports whether v is written on lines of its own below its key.
+func (f *Formatter) expands(v any) bool {
+	switch v := v.(type) {
+	case Group:
+		return len(v) > 0
+	case []any:
+		_, ok := f.inlineArray(v)
+		return !ok
+	default:
+		return false
+	}
+}
+
+// writeArray writes the elements of arr as a list of attrs keyed by index.
+func (f *Formatter) writeArray(b *bytes.Buffer, arr []any, depth int) {
+	for i, v := range arr {
+		f.writeAttr(b, Attr{Key: "[" + strconv.Itoa(i) + "]", Value: v}, depth)
+	}
+}
+
+// inlineArray returns arr as inline JSON if it holds only scalars and fits
+// in the inline array width.
+func (f *Formatter) inlineArray(arr []any) (string, bool) {
+	for _, v := range arr {
+		switch v.(type) {
+		case Group, map[string]any, []any:
+			return "", false
 		}
 	}
+	s := f.formatScalar(arr)
+	return s, len(arr) == 0 || len(s) <= f.cfg.arrayWidth
 }
 
-func (f *Formatter) writeMap(b *strings.Builder, m map[string]any, prefix string) {
+func (f *Formatter) writeGroup(b *bytes.Buffer, g Group, depth int) {
+	for _, a := range f.ordered(g) {
+		f.writeAttr(b, a, depth)
+	}
+}
+
+// ordered returns g sorted by key if enabled, or as logged otherwise.
+func (f *Formatter) ordered(g Group) Group {
+	if !f.cfg.sortKeys {
+		return g
+	}
+	g = slices.Clone(g)
+	slices.SortStableFunc(g, func(a, b Attr) int {
+		return strings.Compare(a.Key, b.Key)
+	})
+	return g
+}
+
+// writeMap writes a map built by hand (rather than parsed) in key order,
+// since maps carry no order of their own.
+func (f *Formatter) writeMap(b *bytes.Buffer, m map[string]any, depth int) {
+	for _, k := range sortedKeys(m) {
+		f.writeAttr(b, Attr{Key: k, Value: m[k]}, depth)
+	}
+}
+
+func sortedKeys(m map[string]any) []string {
 	keys := make([]string, 0, len(m))
 	for k := range m {
 		keys = append(keys, k)
 	}
 	slices.Sort(keys)
-
-	for i, k := range keys {
-		b.WriteString(prefix)
-		b.WriteString(colorize(k, cyan, f.cfg.noColor))
-		b.WriteString(colorize("=", gray, f.cfg.noColor))
-
-		switch v := m[k].(type) {
-		case map[string]any:
-			b.WriteByte('\n')
-			f.writeMap(b, v, prefix+f.cfg.indent)
-		default:
-			b.WriteString(f.formatScalar(m[k]))
-		}
-
-		if i < len(keys)-1 {
-			b.WriteByte('\n')
-		}
-	}
+	return keys
 }
 
 func (f *Formatter) formatScalar(v any) string {
@@ -131,3 +288,23 @@ func (f *Formatter) formatScalar(v any) string {
 		return fmt.Sprintf("%v", v)
 	}
 }
+
+// writeColored writes text wrapped in color unless colors are disabled.
+func (f *Formatter) writeColored(b *bytes.Buffer, text string, color Color) {
+	f.setColor(b, color)
+	b.WriteString(text)
+	f.resetColor(b, color)
+}
+
+func (f *Formatter) setColor(b *bytes.Buffer, color Color) {
+	if !f.cfg.noColor {
+		b.WriteString(string(color))
+	}
+}
+
+// resetColor ends color, which writes nothing for an empty color.
+func (f *Formatter) resetColor(b *bytes.Buffer, color Color) {
+	if !f.cfg.noColor && color != "" {
+		b.WriteString(reset)
+	}
+}
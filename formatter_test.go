@@ -49,6 +49,142 @@ func TestFormatter_Format(t *testing.T) {
 				"(main.run /app/main.go:42)",
 			},
 		},
+		{
+			name: "source without function",
+			opts: []spretty.Option{spretty.WithNoColor()},
+			record: spretty.Record{
+				Level:   "INFO",
+				Message: "started",
+				Source:  &spretty.Source{File: "app/main.go", Line: 42},
+			},
+			contains: []string{"started (app/main.go:42)"},
+		},
+		{
+			name: "labels",
+			opts: []spretty.Option{spretty.WithNoColor()},
+			record: spretty.Record{
+				Level:   "INFO",
+				Message: "hello",
+				Labels:  []string{"api-1"},
+			},
+			contains: []string{"api-1 | INFO  hello"},
+		},
+		{
+			name: "compact",
+			opts: []spretty.Option{spretty.WithNoColor(), spretty.WithCompact()},
+			record: spretty.Record{
+				Level:   "ERROR",
+				Message: "failed\nbadly",
+				Source:  &spretty.Source{File: "/app/main.go", Line: 42},
+				Attrs: []spretty.Attr{
+					{Key: "err", Value: "conn refused"},
+					{Key: "stack", Value: "a\nb"},
+					{Key: "req", Value: spretty.Group{
+						{Key: "method", Value: "GET"},
+						{Key: "headers", Value: spretty.Group{{Key: "host", Value: "example.com"}}},
+						{Key: "meta", Value: spretty.Group{}},
+					}},
+					{Key: "ids", Value: []any{json.Number("1"), json.Number("2")}},
+					{Key: "empty", Value: ""},
+					{Key: "ok", Value: true},
+				},
+			},
+			contains: []string{
+				`ERROR "failed\nbadly" (/app/main.go:42) err="conn refused" stack="a\nb" req.method=GET ` +
+					`req.headers.host=example.com req.meta={} ids=[1,2] empty="" ok=true`,
+			},
+			excludes: []string{"\n"},
+		},
+		{
+			name: "compact with expanded JSON",
+			opts: []spretty.Option{spretty.WithNoColor(), spretty.WithCompact(), spretty.WithExpandJSON()},
+			record: spretty.Record{
+				Message: "request",
+				Attrs:   []spretty.Attr{{Key: "body", Value: `{"id":1,"user":{"name":"a b"}}`}},
+			},
+			contains: []string{`request body.id=1 body.user.name="a b"`},
+		},
+		{
+			name: "multi-line attr as indented block",
+			opts: []spretty.Option{spretty.WithNoColor()},
+			record: spretty.Record{
+				Level:   "ERROR",
+				Message: "panic",
+				Attrs: []spretty.Attr{
+					{Key: "stack", Value: "goroutine 1 [running]:\nmain.main()\n\t/app/main.go:42 +0x1d\n"},
+					{Key: "req", Value: spretty.Group{{Key: "body", Value: "a\r\nb"}}},
+					{Key: "user", Value: "alice"},
+				},
+			},
+			contains: []string{
+				"\n  stack=\n    goroutine 1 [running]:\n    main.main()\n    \t/app/main.go:42 +0x1d\n  req=",
+				"\n    body=\n      a\n      b\n  user=alice",
+			},
+			excludes: []string{"\r"},
+		},
+		{
+			name: "multi-line message as indented block",
+			opts: []spretty.Option{spretty.WithNoColor()},
+			record: spretty.Record{
+				Level:   "ERROR",
+				Message: "query failed:\nSELECT 1\nFROM t",
+				Source:  &spretty.Source{File: "/app/db.go", Line: 7},
+				Attrs:   []spretty.Attr{{Key: "db", Value: "main"}},
+			},
+			contains: []string{"ERROR query failed: (/app/db.go:7)\n  SELECT 1\n  FROM t\n  db=main"},
+		},
+		{
+			name: "JSON strings expanded",
+			opts: []spretty.Option{spretty.WithNoColor(), spretty.WithExpandJSON()},
+			record: spretty.Record{
+				Level:   "INFO",
+				Message: "request",
+				Attrs: []spretty.Attr{
+					{Key: "body", Value: `{"id":1,"user":{"name":"alice"},"raw":"{\"n\":2}"}`},
+					{Key: "ids", Value: ` [1, 2]`},
+					{Key: "empty", Value: `{}`},
+					{Key: "text", Value: `{not json}`},
+				},
+			},
+			contains: []string{
+				"\n  body=(json)\n    id=1\n    user=\n      name=alice\n    raw=(json)\n      n=2",
+				"\n  ids=(json) [1,2]",
+				"\n  empty=(json) {}",
+				"\n  text={not json}",
+			},
+		},
+		{
+			name: "JSON strings kept as text by default",
+			opts: []spretty.Option{spretty.WithNoColor()},
+			record: spretty.Record{
+				Level:   "INFO",
+				Message: "request",
+				Attrs:   []spretty.Attr{{Key: "body", Value: `{"id":1}`}},
+			},
+			contains: []string{`body={"id":1}`},
+			excludes: []string{"(json)"},
+		},
+		{
+			name: "stderr stream",
+			opts: []spretty.Option{spretty.WithNoColor()},
+			record: spretty.Record{
+				Level:   "ERROR",
+				Message: "boom",
+				Labels:  []string{"api-1"},
+				Stream:  "stderr",
+			},
+			contains: []string{"api-1 | stderr | ERROR boom"},
+		},
+		{
+			name: "stdout stream is not marked",
+			opts: []spretty.Option{spretty.WithNoColor()},
+			record: spretty.Record{
+				Level:   "INFO",
+				Message: "ok",
+				Stream:  "stdout",
+			},
+			excludes: []string{"stdout"},
+		},
 		{
 			name: "with attributes",
 			opts: []spretty.Option{spretty.WithNoColor()},
@@ -84,6 +220,119 @@ func TestFormatter_Format(t *testing.T) {
 				"table=users",
 			},
 		},
+		{
+			name: "group keeps key order",
+			opts: []spretty.Option{spretty.WithNoColor()},
+			record: spretty.Record{
+				Level:   "INFO",
+				Message: "req",
+				Attrs: []spretty.Attr{
+					{Key: "params", Value: spretty.Group{
+						{Key: "table", Value: "users"},
+						{Key: "limit", Value: json.Number("100")},
+					}},
+				},
+			},
+			contains: []string{"params=\n    table=users\n    limit=100"},
+		},
+		{
+			name: "group sorted with WithSortKeys",
+			opts: []spretty.Option{spretty.WithNoColor(), spretty.WithSortKeys()},
+			record: spretty.Record{
+				Level:   "INFO",
+				Message: "req",
+				Attrs: []spretty.Attr{
+					{Key: "params", Value: spretty.Group{
+						{Key: "table", Value: "users"},
+						{Key: "limit", Value: json.Number("100")},
+					}},
+				},
+			},
+			contains: []string{"params=\n    limit=100\n    table=users"},
+		},
+		{
+			name: "empty group",
+			opts: []spretty.Option{spretty.WithNoColor()},
+			record: spretty.Record{
+				Level:   "INFO",
+				Message: "req",
+				Attrs: []spretty.Attr{
+					{Key: "params", Value: spretty.Group{}},
+					{Key: "n", Value: json.Number("1")},
+				},
+			},
+			contains: []string{"params={}\n  n=1"},
+		},
+		{
+			name: "group inside array keeps key order",
+			opts: []spretty.Option{spretty.WithNoColor()},
+			record: spretty.Record{
+				Level:   "INFO",
+				Message: "req",
+				Attrs: []spretty.Attr{
+					{Key: "items", Value: []any{spretty.Group{{Key: "z", Value: "1"}, {Key: "a", Value: "2"}}}},
+				},
+			},
+			contains: []string{"items=\n    [0]=\n      z=1\n      a=2"},
+		},
+		{
+			name: "compact keeps arrays inline",
+			opts: []spretty.Option{spretty.WithNoColor(), spretty.WithCompact()},
+			record: spretty.Record{
+				Level:   "INFO",
+				Message: "req",
+				Attrs: []spretty.Attr{
+					{Key: "items", Value: []any{spretty.Group{{Key: "z", Value: "1"}, {Key: "a", Value: "2"}}}},
+				},
+			},
+			contains: []string{`items="[{\"z\":\"1\",\"a\":\"2\"}]"`},
+		},
+		{
+			name: "array of objects as indented list",
+			opts: []spretty.Option{spretty.WithNoColor()},
+			record: spretty.Record{
+				Level:   "WARN",
+				Message: "invalid input",
+				Attrs: []spretty.Attr{
+					{Key: "errors", Value: []any{
+						spretty.Group{{Key: "field", Value: "email"}, {Key: "codes", Value: []any{"required"}}},
+						spretty.Group{{Key: "field", Value: "age"}, {Key: "codes", Value: []any{}}},
+						[]any{json.Number("1"), []any{}},
+					}},
+					{Key: "n", Value: json.Number("2")},
+				},
+			},
+			contains: []string{
+				"\n  errors=\n    [0]=\n      field=email\n      codes=[\"required\"]\n    [1]=\n      field=age\n      codes=[]" +
+					"\n    [2]=\n      [0]=1\n      [1]=[]\n  n=2",
+			},
+		},
+		{
+			name: "long scalar array as indented list",
+			opts: []spretty.Option{spretty.WithNoColor(), spretty.WithArrayWidth(10)},
+			record: spretty.Record{
+				Level:   "INFO",
+				Message: "test",
+				Attrs: []spretty.Attr{
+					{Key: "short", Value: []any{json.Number("1"), json.Number("2")}},
+					{Key: "hosts", Value: []any{"db-1.local", "db-2.local"}},
+				},
+			},
+			contains: []string{"short=[1,2]", "hosts=\n    [0]=db-1.local\n    [1]=db-2.local"},
+		},
+		{
+			name: "zero array width expands every array",
+			opts: []spretty.Option{spretty.WithNoColor(), spretty.WithArrayWidth(0)},
+			record: spretty.Record{
+				Level:   "INFO",
+				Message: "test",
+				Attrs: []spretty.Attr{
+					{Key: "ids", Value: []any{json.Number("1")}},
+					{Key: "none", Value: []any{}},
+				},
+			},
+			contains: []string{"ids=\n    [0]=1", "none=[]"},
+		},
 		{
 			name: "custom time format",
 			opts: []spretty.Option{
@@ -126,14 +126,6 @@ func slogAttrToAttr(groups []string, a slog.Attr) Attr {
 		key = groups[i] + "." + key
 	}
 
-	if a.Value.Kind() == slog.KindGroup {
-		m := make(map[string]any)
-		for _, ga := range a.Value.Group() {
-			m[ga.Key] = slogValueToAny(ga.Value)
-		}
-		return Attr{Key: key, Value: m}
-	}
-
 	return Attr{Key: key, Value: slogValueToAny(a.Value)}
 }
 
@@ -141,11 +133,12 @@ func slogValueToAny(v slog.Value) any {
 	//exhaustive:enforce
 	switch v.Kind() {
 	case slog.KindGroup:
-		m := make(map[string]any)
-		for _, a := range v.Group() {
-			m[a.Key] = slogValueToAny(a.Value)
+		attrs := v.Group()
+		g := make(Group, 0, len(attrs))
+		for _, a := range attrs {
+			g = append(g, Attr{Key: a.Key, Value: slogValueToAny(a.Value)})
 		}
-		return m
+		return g
 	case slog.KindLogValuer:
 		return slogValueToAny(v.Resolve())
 	case slog.KindAny, slog.KindBool, slog.KindDuration,
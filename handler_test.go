@@ -49,6 +49,16 @@ func TestHandler_Handle(t *testing.T) {
 			contains: []string{"visible"},
 			excludes: []string{"hidden"},
 		},
+		{
+			name:  "compact",
+			hopts: nil,
+			opts:  []spretty.Option{spretty.WithNoColor(), spretty.WithCompact()},
+			log: func(l *slog.Logger) {
+				l.With("app", "api").Info("handled", slog.Group("req", "method", "GET", "path", "/a b"))
+			},
+			contains: []string{`INFO  handled app=api req.method=GET req.path="/a b"` + "\n"},
+			excludes: []string{"\n  "},
+		},
 		{
 			name:  "with group",
 			hopts: nil,
@@ -97,7 +107,7 @@ func TestHandler_Handle(t *testing.T) {
 					),
 				)
 			},
-			contains: []string{"params=", "table=users"},
+			contains: []string{"params=\n    table=users\n    limit=100"},
 		},
 		{
 			name:  "ignore keys",
@@ -0,0 +1,114 @@
+package spretty
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"time"
+)
+
+// Fields of a `journalctl -o json` entry read in journald mode.
+const (
+	journalMessage  = "MESSAGE"
+	journalPriority = "PRIORITY"
+	journalTime     = "__REALTIME_TIMESTAMP"
+	journalUnit     = "_SYSTEMD_UNIT"
+)
+
+// parseJournald parses a journald entry. The record the service logged is
+// read from MESSAGE; when MESSAGE is plain text, it becomes the message and
+// PRIORITY the level. The entry's time stands in for a missing record time,
+// and the systemd unit becomes a label.
+func (p *Parser) parseJournald(line []byte, rec *Record, d *decoder) error {
+	start := len(line) - len(bytes.TrimLeft(line, " \t\r\n"))
+	if start == len(line) {
+		return parseErrorf(-1, "empty line")
+	}
+
+	d.reset(line)
+	d.pos = start
+	entry, err := d.object()
+	if err != nil {
+		return err
+	}
+	if d.peek() != 0 {
+		return d.errorf("unexpected %q after JSON object", d.data[d.pos])
+	}
+
+	var msg, priority, realtime, unit string
+	var hasMsg bool
+	for _, a := range entry {
+		switch a.Key {
+		case journalMessage:
+			msg, hasMsg = journalString(a.Value)
+		case journalPriority:
+			priority, _ = a.Value.(string)
+		case journalTime:
+			realtime, _ = a.Value.(string)
+		case journalUnit:
+			unit, _ = a.Value.(string)
+		}
+	}
+	if !hasMsg {
+		return parseErrorf(-1, "no %s field in journal entry", journalMessage)
+	}
+
+	if p.parseLine([]byte(msg), rec, d) != nil {
+		rec.Reset()
+		rec.Message = msg
+	}
+	if rec.Level == "" {
+		rec.Level = priorityLevel(priority)
+	}
+	if us, err := strconv.ParseInt(realtime, 10, 64); err == nil && rec.Time.IsZero() {
+		rec.Time = time.UnixMicro(us).UTC()
+	}
+	if unit != "" {
+		rec.Labels = append(rec.Labels, unit)
+	}
+	return nil
+}
+
+// journalString returns a journal field as a string. journald writes fields
+// that are not valid UTF-8 as arrays of byte values, and null for fields too
+// large to show.
+func journalString(v any) (string, bool) {
+	switch v := v.(type) {
+	case string:
+		return v, true
+	case nil:
+		return "", true
+	case []any:
+		b := make([]byte, 0, len(v))
+		for _, e := range v {
+			n, ok := e.(json.Number)
+			if !ok {
+				return "", false
+			}
+			c, err := strconv.ParseUint(n.String(), 10, 8)
+			if err != nil {
+				return "", false
+			}
+			b = append(b, byte(c))
+		}
+		return string(b), true
+	default:
+		return "", false
+	}
+}
+
+// priorityLevel maps a syslog priority (0 emerg to 7 debug) to a level name.
+func priorityLevel(priority string) string {
+	switch priority {
+	case "0", "1", "2", "3":
+		return "ERROR"
+	case "4":
+		return "WARN"
+	case "5", "6":
+		return "INFO"
+	case "7":
+		return "DEBUG"
+	default:
+		return ""
+	}
+}
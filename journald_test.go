@@ -0,0 +1,101 @@
+package spretty_test
+
+import (
+	"testing"
+	"time"
+
+	spretty "github.com/mickamy/slog-pretty"
+)
+
+func TestParse_Journald(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		input  string
+		wantOK bool
+		check  func(t *testing.T, r *spretty.Record)
+	}{
+		{
+			name: "slog record in MESSAGE",
+			input: `{"__REALTIME_TIMESTAMP":"1772100930123456","PRIORITY":"6","_SYSTEMD_UNIT":"api.service",` +
+				`"MESSAGE":"{\"time\":\"2026-02-26T10:15:30Z\",\"level\":\"WARN\",\"msg\":\"slow\",\"ms\":120}"}`,
+			wantOK: true,
+			check: func(t *testing.T, r *spretty.Record) {
+				t.Helper()
+				if r.Level != "WARN" || r.Message != "slow" {
+					t.Errorf("Level, Message = %q, %q, want WARN, slow", r.Level, r.Message)
+				}
+				want := time.Date(2026, 2, 26, 10, 15, 30, 0, time.UTC)
+				if !r.Time.Equal(want) {
+					t.Errorf("Time = %v, want %v", r.Time, want)
+				}
+				if len(r.Attrs) != 1 || r.Attrs[0].Key != "ms" {
+					t.Errorf("Attrs = %v, want [ms]", r.Attrs)
+				}
+				if len(r.Labels) != 1 || r.Labels[0] != "api.service" {
+					t.Errorf("Labels = %q, want [api.service]", r.Labels)
+				}
+			},
+		},
+		{
+			name:   "plain MESSAGE uses PRIORITY and entry time",
+			input:  `{"__REALTIME_TIMESTAMP":"1772100930123456","PRIORITY":"3","MESSAGE":"disk full"}`,
+			wantOK: true,
+			check: func(t *testing.T, r *spretty.Record) {
+				t.Helper()
+				if r.Level != "ERROR" || r.Message != "disk full" {
+					t.Errorf("Level, Message = %q, %q, want ERROR, disk full", r.Level, r.Message)
+				}
+				want := time.Date(2026, 2, 26, 10, 15, 30, 123456000, time.UTC)
+				if !r.Time.Equal(want) {
+					t.Errorf("Time = %v, want %v", r.Time, want)
+				}
+				if len(r.Labels) != 0 {
+					t.Errorf("Labels = %q, want none", r.Labels)
+				}
+			},
+		},
+		{
+			name:   "record without level uses PRIORITY",
+			input:  `{"PRIORITY":"4","MESSAGE":"{\"msg\":\"hi\"}"}`,
+			wantOK: true,
+			check:  checkLevel("WARN"),
+		},
+		{
+			name:   "MESSAGE as byte array",
+			input:  `{"PRIORITY":"7","MESSAGE":[104,105,255]}`,
+			wantOK: true,
+			check: func(t *testing.T, r *spretty.Record) {
+				t.Helper()
+				if r.Level != "DEBUG" || r.Message != "hi\xff" {
+					t.Errorf("Level, Message = %q, %q, want DEBUG, hi\\xff", r.Level, r.Message)
+				}
+			},
+		},
+		{
+			name:   "no MESSAGE",
+			input:  `{"PRIORITY":"6"}`,
+			wantOK: false,
+		},
+		{
+			name:   "not JSON",
+			input:  `-- Boot 1f2e --`,
+			wantOK: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			rec, err := spretty.NewParser(spretty.WithJournald()).Parse([]byte(tt.input))
+			if ok := err == nil; ok != tt.wantOK {
+				t.Fatalf("Parse() error = %v, wantOK %v", err, tt.wantOK)
+			}
+			if tt.check != nil && rec != nil {
+				tt.check(t, rec)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package spretty
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Severity reports the slog level of r. Offsets such as "INFO+2" are kept,
+// and levels from other loggers (TRACE, FATAL, PANIC, ...) map onto the
+// nearest slog level. It returns false for levels it does not recognize.
+func (r *Record) Severity() (slog.Level, bool) {
+	return levelSeverity(r.Level)
+}
+
+func levelSeverity(level string) (slog.Level, bool) {
+	switch level {
+	case "":
+		return 0, false
+	case "TRACE":
+		return slog.LevelDebug - 4, true
+	case "DEBUG":
+		return slog.LevelDebug, true
+	case "INFO":
+		return slog.LevelInfo, true
+	case "WARN":
+		return slog.LevelWarn, true
+	case "ERROR":
+		return slog.LevelError, true
+	case "FATAL", "PANIC", "DPANIC", "CRITICAL":
+		return slog.LevelError + 4, true
+	}
+	var l slog.Level
+	if err := l.UnmarshalText([]byte(level)); err != nil {
+		return 0, false
+	}
+	return l, true
+}
+
+// normalizeLevel maps dialect-specific level names onto slog's spelling.
+func normalizeLevel(level string) string {
+	upper := strings.ToUpper(level)
+	if upper == "WARNING" {
+		return "WARN"
+	}
+	if upper != "" && (upper[0] == '-' || (upper[0] >= '0' && upper[0] <= '9')) {
+		if n, err := strconv.Atoi(upper); err == nil {
+			return numericLevel(n)
+		}
+	}
+	return upper
+}
+
+// numericLevel names a numeric level. Multiples of ten from 10 to 60 are read
+// as pino/bunyan levels; anything else is an [slog.Level].
+func numericLevel(n int) string {
+	switch n {
+	case 10:
+		return (slog.LevelDebug - 4).String()
+	case 20:
+		return slog.LevelDebug.String()
+	case 30:
+		return slog.LevelInfo.String()
+	case 40:
+		return slog.LevelWarn.String()
+	case 50:
+		return slog.LevelError.String()
+	case 60:
+		return "FATAL"
+	default:
+		return slog.Level(n).String()
+	}
+}
+
+// parseLevel reads a level written as a string or a number. Integral floats
+// such as 30.0 are accepted as well.
+func parseLevel(v any) (string, error) {
+	switch v := v.(type) {
+	case string:
+		return normalizeLevel(v), nil
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return numericLevel(int(n)), nil
+		}
+		f, err := v.Float64()
+		if err != nil || f != math.Trunc(f) || math.Abs(f) > math.MaxInt32 {
+			return "", fmt.Errorf("%s is not an integral level", v)
+		}
+		return numericLevel(int(f)), nil
+	default:
+		return "", fmt.Errorf("%s is not a string or number", jsonType(v))
+	}
+}
@@ -0,0 +1,45 @@
+package spretty_test
+
+import (
+	"log/slog"
+	"testing"
+
+	spretty "github.com/mickamy/slog-pretty"
+)
+
+func TestRecord_Severity(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		level  string
+		want   slog.Level
+		wantOK bool
+	}{
+		{name: "INFO", level: "INFO", want: slog.LevelInfo, wantOK: true},
+		{name: "DEBUG offset", level: "DEBUG-4", want: slog.LevelDebug - 4, wantOK: true},
+		{name: "INFO offset", level: "INFO+2", want: slog.LevelInfo + 2, wantOK: true},
+		{name: "WARN offset", level: "WARN+1", want: slog.LevelWarn + 1, wantOK: true},
+		{name: "FATAL", level: "FATAL", want: slog.LevelError + 4, wantOK: true},
+		{name: "PANIC", level: "PANIC", want: slog.LevelError + 4, wantOK: true},
+		{name: "TRACE", level: "TRACE", want: slog.LevelDebug - 4, wantOK: true},
+		{name: "unknown", level: "NOTICE", wantOK: false},
+		{name: "empty", level: "", wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_ = t.Context()
+
+			r := spretty.Record{Level: tt.level}
+			got, ok := r.Severity()
+			if ok != tt.wantOK {
+				t.Fatalf("Severity() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if ok && got != tt.want {
+				t.Errorf("Severity() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
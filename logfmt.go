@@ -0,0 +1,151 @@
+package spretty
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+// parseLogfmt parses a slog TextHandler (logfmt) line into a Record.
+// Every space-separated token must be a key=value pair; dotted keys written
+// for slog groups are nested the same way JSONHandler nests them.
+// Parsing starts at byte start of line.
+func (p *Parser) parseLogfmt(line []byte, start int, rec *Record) error {
+	var slots fieldSlots
+
+	full := strings.TrimRight(string(line), " \t\r\n")
+	s := full[start:]
+	for s != "" {
+		off := len(full) - len(s)
+		pair, rest, err := nextLogfmtPair(s)
+		if err != nil {
+			err.Offset += off
+			return err
+		}
+		key, val, quoted := pair.key, pair.val, pair.quoted
+		s = strings.TrimLeft(rest, " \t")
+
+		if f, _, rank, ok := lookupKey(&p.dialect, key); ok {
+			sl := slot{key: key, str: val, isStr: true, off: off + pair.valOff}
+			if !quoted && (f == fieldTime || f == fieldSource) {
+				sl = slot{key: key, val: logfmtValue(val), off: off + pair.valOff}
+			}
+			if p.claim(rec, &slots, f, rank, sl) {
+				continue
+			}
+		}
+
+		var v any = val
+		if !quoted {
+			v = logfmtValue(val)
+		}
+		rec.Attrs = appendDotted(rec.Attrs, key, v)
+	}
+
+	return p.finishRecord(rec, &slots)
+}
+
+// logfmtPair is one key=value pair of a logfmt line.
+type logfmtPair struct {
+	key, val string
+	quoted   bool
+	valOff   int // byte offset of the value from the start of the pair
+}
+
+// nextLogfmtPair reads one key=value pair from the start of s and returns it
+// with the rest of s. Error offsets count from the start of s.
+func nextLogfmtPair(s string) (logfmtPair, string, *ParseError) {
+	var pair logfmtPair
+	key, rest, err := logfmtToken(s, true)
+	if err != nil {
+		return pair, "", err
+	}
+	if key == "" || !strings.HasPrefix(rest, "=") {
+		return pair, "", parseErrorf(0, "expected key=value pair")
+	}
+	rest = rest[1:]
+	pair.key = key
+	pair.valOff = len(s) - len(rest)
+	pair.quoted = strings.HasPrefix(rest, `"`)
+	if pair.val, rest, err = logfmtToken(rest, false); err != nil {
+		err.Offset += pair.valOff
+		return pair, "", err
+	}
+	if rest != "" && rest[0] != ' ' && rest[0] != '\t' {
+		return pair, "", parseErrorf(len(s)-len(rest), "unexpected %q after quoted value", rest[0])
+	}
+	return pair, rest, nil
+}
+
+// logfmtToken reads a bare or Go-quoted token from the start of s.
+// Bare keys end at '=' or whitespace; bare values end at whitespace.
+// Error offsets count from the start of s.
+func logfmtToken(s string, isKey bool) (string, string, *ParseError) {
+	if strings.HasPrefix(s, `"`) {
+		for i := 1; i < len(s); i++ {
+			switch s[i] {
+			case '\\':
+				i++
+			case '"':
+				tok, err := strconv.Unquote(s[:i+1])
+				if err != nil {
+					return "", "", parseErrorf(0, "invalid quoted string")
+				}
+				return tok, s[i+1:], nil
+			}
+		}
+		return "", "", parseErrorf(0, "unterminated quoted string")
+	}
+
+	end := strings.IndexAny(s, " \t")
+	if isKey {
+		if eq := strings.IndexByte(s, '='); eq >= 0 && (end < 0 || eq < end) {
+			end = eq
+		}
+	}
+	if end < 0 {
+		end = len(s)
+	}
+	tok := s[:end]
+	if q := strings.IndexByte(tok, '"'); q >= 0 {
+		return "", "", parseErrorf(q, "unexpected '\"' in unquoted token")
+	}
+	return tok, s[end:], nil
+}
+
+// logfmtValue types a bare logfmt value the way JSONHandler would have
+// written it: numbers and booleans keep their type, anything else is a string.
+func logfmtValue(s string) any {
+	switch s {
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+	if s != "" && json.Valid([]byte(s)) && (s[0] == '-' || (s[0] >= '0' && s[0] <= '9')) {
+		return json.Number(s)
+	}
+	return s
+}
+
+// appendDotted appends key=v to attrs, nesting a dotted key such as
+// "req.method" under a new "req" group, or under the last attr if that is the
+// "req" group already. TextHandler writes the keys of a group next to each
+// other, so only the last attr needs to be looked at, which keeps long lines
+// linear.
+func appendDotted(attrs []Attr, key string, v any) []Attr {
+	head, tail, ok := strings.Cut(key, ".")
+	if !ok || head == "" || tail == "" {
+		return append(attrs, Attr{Key: key, Value: v})
+	}
+
+	if n := len(attrs); n > 0 && attrs[n-1].Key == head {
+		if g, ok := attrs[n-1].Value.(Group); ok {
+			attrs[n-1].Value = Group(appendDotted(g, tail, v))
+			return attrs
+		}
+		return append(attrs, Attr{Key: key, Value: v})
+	}
+
+	return append(attrs, Attr{Key: head, Value: Group(appendDotted(nil, tail, v))})
+}
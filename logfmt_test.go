@@ -0,0 +1,190 @@
+package spretty_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	spretty "github.com/mickamy/slog-pretty"
+)
+
+func TestParse_Logfmt(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		input  string
+		wantOK bool
+		check  func(t *testing.T, r *spretty.Record)
+	}{
+		{
+			name:   "TextHandler line",
+			input:  `time=2026-02-26T10:15:30.123Z level=INFO msg="server started" port=8080 host=localhost`,
+			wantOK: true,
+			check: func(t *testing.T, r *spretty.Record) {
+				t.Helper()
+				wantTime := time.Date(2026, 2, 26, 10, 15, 30, 123000000, time.UTC)
+				if !r.Time.Equal(wantTime) {
+					t.Errorf("Time = %v, want %v", r.Time, wantTime)
+				}
+				if r.Level != "INFO" {
+					t.Errorf("Level = %q, want %q", r.Level, "INFO")
+				}
+				if r.Message != "server started" {
+					t.Errorf("Message = %q, want %q", r.Message, "server started")
+				}
+				if len(r.Attrs) != 2 {
+					t.Fatalf("Attrs length = %d, want 2", len(r.Attrs))
+				}
+				if r.Attrs[0].Value != json.Number("8080") {
+					t.Errorf("port = %#v, want json.Number(8080)", r.Attrs[0].Value)
+				}
+				if r.Attrs[1].Value != "localhost" {
+					t.Errorf("host = %#v, want %q", r.Attrs[1].Value, "localhost")
+				}
+			},
+		},
+		{
+			name:   "escaped quotes",
+			input:  `level=WARN msg="said \"hi\"\nbye" path="C:\\tmp"`,
+			wantOK: true,
+			check: func(t *testing.T, r *spretty.Record) {
+				t.Helper()
+				if r.Message != "said \"hi\"\nbye" {
+					t.Errorf("Message = %q", r.Message)
+				}
+				if r.Attrs[0].Value != `C:\tmp` {
+					t.Errorf("path = %#v, want %q", r.Attrs[0].Value, `C:\tmp`)
+				}
+			},
+		},
+		{
+			name:   "quoted values keep string type",
+			input:  `level=INFO msg=hi code="200" ok=true raw="true"`,
+			wantOK: true,
+			check: func(t *testing.T, r *spretty.Record) {
+				t.Helper()
+				want := []any{"200", true, "true"}
+				for i, w := range want {
+					if r.Attrs[i].Value != w {
+						t.Errorf("Attrs[%d].Value = %#v, want %#v", i, r.Attrs[i].Value, w)
+					}
+				}
+			},
+		},
+		{
+			name:   "dotted group keys",
+			input:  `level=INFO msg=req req.method=GET req.headers.host=example.com req.path=/`,
+			wantOK: true,
+			check: func(t *testing.T, r *spretty.Record) {
+				t.Helper()
+				if len(r.Attrs) != 1 || r.Attrs[0].Key != "req" {
+					t.Fatalf("Attrs = %v, want single req group", r.Attrs)
+				}
+				want := spretty.Group{
+					{Key: "method", Value: "GET"},
+					{Key: "headers", Value: spretty.Group{{Key: "host", Value: "example.com"}}},
+					{Key: "path", Value: "/"},
+				}
+				if !reflect.DeepEqual(r.Attrs[0].Value, want) {
+					t.Errorf("req = %#v, want %#v", r.Attrs[0].Value, want)
+				}
+			},
+		},
+		{
+			name:   "many distinct dotted groups",
+			input:  "level=INFO msg=big" + dottedGroups(50000),
+			wantOK: true,
+			check: func(t *testing.T, r *spretty.Record) {
+				t.Helper()
+				if len(r.Attrs) != 50000 {
+					t.Fatalf("Attrs length = %d, want 50000", len(r.Attrs))
+				}
+				want := spretty.Group{{Key: "x", Value: json.Number("1")}, {Key: "y", Value: json.Number("2")}}
+				if last := r.Attrs[len(r.Attrs)-1]; last.Key != "k49999" || !reflect.DeepEqual(last.Value, want) {
+					t.Errorf("last attr = %#v, want k49999=%#v", last, want)
+				}
+			},
+		},
+		{
+			name:   "source location",
+			input:  `level=ERROR source=/app/main.go:42 msg=fail`,
+			wantOK: true,
+			check: func(t *testing.T, r *spretty.Record) {
+				t.Helper()
+				if r.Source == nil || r.Source.File != "/app/main.go" || r.Source.Line != 42 {
+					t.Errorf("Source = %+v, want /app/main.go:42", r.Source)
+				}
+			},
+		},
+		{
+			name:   "source location with function",
+			input:  `level=ERROR source="main.run /app/main.go:42" msg=fail`,
+			wantOK: true,
+			check: func(t *testing.T, r *spretty.Record) {
+				t.Helper()
+				want := spretty.Source{Function: "main.run", File: "/app/main.go", Line: 42}
+				if r.Source == nil || *r.Source != want {
+					t.Errorf("Source = %+v, want %+v", r.Source, want)
+				}
+			},
+		},
+		{
+			name:   "empty value",
+			input:  `level=INFO msg= user=`,
+			wantOK: true,
+			check: func(t *testing.T, r *spretty.Record) {
+				t.Helper()
+				if r.Message != "" || len(r.Attrs) != 1 || r.Attrs[0].Value != "" {
+					t.Errorf("Record = %+v", r)
+				}
+			},
+		},
+		{
+			name:   "plain text",
+			input:  "server listening on :8080",
+			wantOK: false,
+		},
+		{
+			name:   "bare word among pairs",
+			input:  "level=INFO msg=hi oops",
+			wantOK: false,
+		},
+		{
+			name:   "no core fields",
+			input:  "user=alice role=admin",
+			wantOK: false,
+		},
+		{
+			name:   "unterminated quote",
+			input:  `level=INFO msg="oops`,
+			wantOK: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			rec, err := spretty.Parse([]byte(tt.input))
+			if ok := err == nil; ok != tt.wantOK {
+				t.Fatalf("Parse() error = %v, wantOK %v", err, tt.wantOK)
+			}
+			if tt.check != nil && rec != nil {
+				tt.check(t, rec)
+			}
+		})
+	}
+}
+
+// dottedGroups returns n pairs of dotted keys, each pair in a group of its own.
+func dottedGroups(n int) string {
+	var b strings.Builder
+	for i := range n {
+		fmt.Fprintf(&b, " k%d.x=1 k%d.y=2", i, i)
+	}
+	return b.String()
+}
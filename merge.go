@@ -0,0 +1,139 @@
+package spretty
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Input is a named source of log lines for [Scanner.Merge].
+type Input struct {
+	Name   string
+	Reader io.Reader
+}
+
+// Merge reads all inputs and writes their lines to w ordered by record time,
+// each labelled with the name of its input. Inputs take the theme's label
+// colors in turn, so that each has a color of its own. Every input is
+// expected to be in time order itself, as log files are; lines without a time
+// of their own, such as passthrough lines, stay right after the record before
+// them.
+func (s *Scanner) Merge(w io.Writer, inputs ...Input) error {
+	width := 0
+	for _, in := range inputs {
+		width = max(width, utf8.RuneCountInString(in.Name))
+	}
+
+	streams := make([]*mergeStream, 0, len(inputs))
+	for i, in := range inputs {
+		m := &mergeStream{
+			name: in.Name,
+			sc: &Scanner{
+				parser:      s.parser,
+				formatter:   s.formatter,
+				dec:         decoder{keys: make(map[string]string)},
+				source:      padRight(in.Name, width),
+				sourceColor: s.formatter.cfg.theme.labelAt(i),
+			},
+			br: bufio.NewReaderSize(in.Reader, readBufferSize),
+		}
+		if startsArray(m.br) {
+			if err := skipArrayStart(m.br); err != nil {
+				return fmt.Errorf("%s: %w", in.Name, err)
+			}
+			m.array = true
+		}
+		if err := m.advance(); err != nil {
+			return fmt.Errorf("%s: %w", in.Name, err)
+		}
+		streams = append(streams, m)
+	}
+
+	for {
+		var next *mergeStream
+		for _, m := range streams {
+			if m.exhausted() {
+				continue
+			}
+			if next == nil || m.time.Before(next.time) {
+				next = m
+			}
+		}
+		if next == nil {
+			return nil
+		}
+
+		if _, err := w.Write(next.out.Bytes()); err != nil {
+			return fmt.Errorf("writing merged line: %w", err)
+		}
+		if err := next.advance(); err != nil {
+			return fmt.Errorf("%s: %w", next.name, err)
+		}
+	}
+}
+
+// mergeStream holds the next output of one merged input.
+type mergeStream struct {
+	name  string
+	sc    *Scanner
+	br    *bufio.Reader
+	array bool // reading the elements of a JSON array
+
+	out  bytes.Buffer // output of the next line, or lines
+	time time.Time    // time to merge out at
+	done bool
+}
+
+// exhausted reports whether the stream has no more output.
+func (m *mergeStream) exhausted() bool {
+	return m.done && m.out.Len() == 0
+}
+
+// advance reads input until it produces output or ends.
+func (m *mergeStream) advance() error {
+	m.out.Reset()
+	for m.out.Len() == 0 && !m.done {
+		if err := m.step(); err != nil {
+			return err
+		}
+	}
+	m.time = m.sc.lastTime
+	return nil
+}
+
+func (m *mergeStream) step() error {
+	if m.array {
+		more, err := m.sc.nextElement(m.br, &m.out)
+		m.array = more
+		return err
+	}
+
+	line, err := m.sc.readLine(m.br)
+	if err != nil && errors.Is(err, io.EOF) && len(line) == 0 {
+		m.done = true
+		return m.sc.flush(&m.out)
+	}
+	if writeErr := m.sc.feedLine(&m.out, line); writeErr != nil {
+		return writeErr
+	}
+	if err != nil {
+		m.done = true
+		if flushErr := m.sc.flush(&m.out); flushErr != nil {
+			return flushErr
+		}
+		if !errors.Is(err, io.EOF) {
+			return fmt.Errorf("reading input: %w", err)
+		}
+	}
+	return nil
+}
+
+// padRight pads s with spaces to width runes.
+func padRight(s string, width int) string {
+	return s + strings.Repeat(" ", max(0, width-utf8.RuneCountInString(s)))
+}
@@ -0,0 +1,147 @@
+package spretty_test
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"testing"
+
+	spretty "github.com/mickamy/slog-pretty"
+)
+
+func TestScanner_Merge(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		inputs map[string]string
+		order  []string
+		opts   []spretty.Option
+		want   string
+	}{
+		{
+			name: "records are ordered by time",
+			inputs: map[string]string{
+				"api": `{"time":"2026-02-26T10:00:00Z","level":"INFO","msg":"request"}
+{"time":"2026-02-26T10:00:02Z","level":"INFO","msg":"response"}
+`,
+				"worker": `{"time":"2026-02-26T10:00:01Z","level":"INFO","msg":"job"}
+{"time":"2026-02-26T10:00:03Z","level":"INFO","msg":"done"}
+`,
+			},
+			order: []string{"api", "worker"},
+			opts:  []spretty.Option{spretty.WithNoColor()},
+			want: `api    | 10:00:00.000 INFO  request
+worker | 10:00:01.000 INFO  job
+api    | 10:00:02.000 INFO  response
+worker | 10:00:03.000 INFO  done
+`,
+		},
+		{
+			name: "untimed lines stay after the record before them",
+			inputs: map[string]string{
+				"a": `starting
+{"time":"2026-02-26T10:00:02Z","level":"ERROR","msg":"panic"}
+goroutine 1 [running]:
+`,
+				"b": `{"time":"2026-02-26T10:00:01Z","level":"INFO","msg":"one"}
+{"time":"2026-02-26T10:00:03Z","level":"INFO","msg":"three"}
+`,
+			},
+			order: []string{"a", "b"},
+			opts:  []spretty.Option{spretty.WithNoColor()},
+			want: `a | starting
+b | 10:00:01.000 INFO  one
+a | 10:00:02.000 ERROR panic
+a | goroutine 1 [running]:
+b | 10:00:03.000 INFO  three
+`,
+		},
+		{
+			name: "equal times keep input order",
+			inputs: map[string]string{
+				"x": `{"time":"2026-02-26T10:00:00Z","level":"INFO","msg":"x"}` + "\n",
+				"y": `{"time":"2026-02-26T10:00:00Z","level":"INFO","msg":"y"}` + "\n",
+			},
+			order: []string{"y", "x"},
+			opts:  []spretty.Option{spretty.WithNoColor()},
+			want: `y | 10:00:00.000 INFO  y
+x | 10:00:00.000 INFO  x
+`,
+		},
+		{
+			name: "JSON array input",
+			inputs: map[string]string{
+				"export": `[{"time":"2026-02-26T10:00:00Z","level":"INFO","msg":"a"},` +
+					`{"time":"2026-02-26T10:00:02Z","level":"INFO","msg":"c"}]`,
+				"live": `{"time":"2026-02-26T10:00:01Z","level":"INFO","msg":"b"}` + "\n",
+			},
+			order: []string{"export", "live"},
+			opts:  []spretty.Option{spretty.WithNoColor()},
+			want: `export | 10:00:00.000 INFO  a
+live   | 10:00:01.000 INFO  b
+export | 10:00:02.000 INFO  c
+`,
+		},
+		{
+			name:   "empty input",
+			inputs: map[string]string{"a": "", "b": ""},
+			order:  []string{"a", "b"},
+			opts:   []spretty.Option{spretty.WithNoColor()},
+			want:   "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			inputs := make([]spretty.Input, 0, len(tt.order))
+			for _, name := range tt.order {
+				inputs = append(inputs, spretty.Input{Name: name, Reader: strings.NewReader(tt.inputs[name])})
+			}
+
+			var buf bytes.Buffer
+			if err := spretty.NewScanner(tt.opts...).Merge(&buf, inputs...); err != nil {
+				t.Fatalf("Merge() error = %v", err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("Merge() output mismatch\ngot:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScanner_Merge_Colors(t *testing.T) {
+	t.Parallel()
+
+	// These names share a color when picked by hash.
+	names := []string{"app.log", "app.log.1.gz", "worker.log"}
+	inputs := make([]spretty.Input, len(names))
+	for i, name := range names {
+		line := `{"time":"2026-02-26T10:00:0` + strconv.Itoa(i) + `Z","level":"INFO","msg":"a"}` + "\n"
+		if i == len(names)-1 {
+			line = "plain\n"
+		}
+		inputs[i] = spretty.Input{Name: name, Reader: strings.NewReader(line)}
+	}
+
+	var buf bytes.Buffer
+	if err := spretty.NewScanner().Merge(&buf, inputs...); err != nil {
+		t.Fatalf("Merge() error = %v", err)
+	}
+
+	got := buf.String()
+	palette := spretty.DarkTheme().Labels
+	seen := make(map[spretty.Color]string)
+	for i, name := range names {
+		color := palette[i]
+		if !strings.Contains(got, string(color)+name) {
+			t.Errorf("output missing %s in %q\ngot: %q", name, color, got)
+		}
+		if other, ok := seen[color]; ok {
+			t.Errorf("%s and %s share color %q", other, name, color)
+		}
+		seen[color] = name
+	}
+}
@@ -0,0 +1,140 @@
+package spretty
+
+import (
+	"bytes"
+	"io"
+)
+
+// depthTracker follows JSON nesting across lines, ignoring brackets that
+// appear inside strings.
+type depthTracker struct {
+	depth    int
+	inString bool
+	escaped  bool
+	broken   bool // more closing than opening brackets, or a string left open
+}
+
+func (t *depthTracker) feed(b []byte) {
+	for _, c := range b {
+		t.feedByte(c)
+	}
+}
+
+func (t *depthTracker) feedByte(c byte) {
+	switch {
+	case t.escaped:
+		t.escaped = false
+	case t.inString:
+		switch c {
+		case '\\':
+			t.escaped = true
+		case '"':
+			t.inString = false
+		}
+	default:
+		switch c {
+		case '"':
+			t.inString = true
+		case '{', '[':
+			t.depth++
+		case '}', ']':
+			t.depth--
+			if t.depth < 0 {
+				t.broken = true
+			}
+		}
+	}
+}
+
+// endLine marks the object broken if a string is left open at the end of a
+// line, since JSON strings cannot span lines.
+func (t *depthTracker) endLine() {
+	if t.inString {
+		t.broken = true
+	}
+}
+
+// open reports whether the tracked object still awaits closing brackets.
+func (t *depthTracker) open() bool {
+	return t.depth > 0 && !t.broken
+}
+
+// feedLine passes line on for processing, or holds it back while it belongs
+// to a JSON object that has not been closed yet. A JSON record on a line of
+// its own, starting at the first column, ends the object, so that a line cut
+// off mid-write, such as by a crash, does not hold back the records after it.
+// Indented lines are never taken for records, since they may hold an object
+// nested in the one being reassembled.
+func (s *Scanner) feedLine(w io.Writer, line []byte) error {
+	if s.pending != nil {
+		if !s.standaloneRecord(line) {
+			return s.feedPending(w, line)
+		}
+		if err := s.flushPending(w); err != nil {
+			return err
+		}
+	}
+
+	if c, ok := s.unwrapContainer(line); ok {
+		return s.feedContainer(w, c)
+	}
+	if err := s.flushJoined(w); err != nil {
+		return err
+	}
+
+	trimmed := bytes.TrimLeft(line, " \t")
+	if len(trimmed) > 0 && trimmed[0] == '{' && len(line) <= s.maxLineSize() {
+		s.depth = depthTracker{}
+		s.depth.feed(line)
+		s.depth.endLine()
+		if s.depth.open() {
+			s.pending = append([]byte(nil), line...)
+			return nil
+		}
+	}
+
+	return s.processLine(w, line)
+}
+
+// feedPending adds line to the object being reassembled, and processes the
+// object once it closes.
+func (s *Scanner) feedPending(w io.Writer, line []byte) error {
+	s.pending = append(s.pending, '\n')
+	s.pending = append(s.pending, line...)
+	s.depth.feed(line)
+	s.depth.endLine()
+
+	switch {
+	case s.depth.open() && len(s.pending) <= s.maxLineSize():
+		return nil
+	case s.depth.open() || s.depth.broken:
+		// The object never closed within the limit; give up on it.
+		return s.flushPending(w)
+	default:
+		buf := s.pending
+		s.pending = nil
+		return s.processLine(w, buf)
+	}
+}
+
+// standaloneRecord reports whether line, met while reassembling an object, is a
+// JSON record of its own.
+func (s *Scanner) standaloneRecord(line []byte) bool {
+	return len(line) > 0 && line[0] == '{' && s.parser.parseInto(line, &s.rec, &s.dec) == nil
+}
+
+// flushPending writes the lines of an unfinished multi-line object unchanged.
+func (s *Scanner) flushPending(w io.Writer) error {
+	if s.pending == nil {
+		return nil
+	}
+	buf := s.pending
+	s.pending = nil
+	if err := writeRaw(w, buf); err != nil {
+		return err
+	}
+	if !s.formatter.cfg.explain {
+		return nil
+	}
+	return s.explain(w, s.parser.parseInto(buf, &s.rec, &s.dec))
+}
@@ -1,25 +1,62 @@
 package spretty
 
+import (
+	"log/slog"
+	"strings"
+)
+
 const (
 	defaultTimeFormat = "15:04:05.000"
 	defaultLevelWidth = 5
 	defaultIndent     = "  "
+	defaultArrayWidth = 60
 )
 
 type config struct {
-	timeFormat  string
-	noColor     bool
-	ignoreKeys  map[string]struct{}
-	levelWidth  int
-	indent      string
-	handlerOpts *HandlerOptions
+	timeFormat   string
+	noColor      bool
+	ignoreKeys   map[string]struct{}
+	levelWidth   int
+	indent       string
+	keys         keys
+	format       Format
+	timeLayouts  []string
+	sortKeys     bool
+	minLevel     slog.Leveler
+	explain      bool
+	journald     bool
+	envelope     []string
+	keepEnvelope bool
+	maxLineSize  int
+	expandJSON   bool
+	compact      bool
+	arrayWidth   int
+	theme        Theme
+	handlerOpts  *HandlerOptions
+}
+
+// keys holds the JSON key names of the core record fields.
+type keys struct {
+	time    string
+	level   string
+	message string
+	source  string
 }
 
 func newConfig(opts []Option) config {
 	c := config{
-		timeFormat: defaultTimeFormat,
-		levelWidth: defaultLevelWidth,
-		indent:     defaultIndent,
+		timeFormat:  defaultTimeFormat,
+		levelWidth:  defaultLevelWidth,
+		maxLineSize: defaultMaxLineSize,
+		indent:      defaultIndent,
+		arrayWidth:  defaultArrayWidth,
+		theme:       DarkTheme(),
+		keys: keys{
+			time:    slog.TimeKey,
+			level:   slog.LevelKey,
+			message: slog.MessageKey,
+			source:  slog.SourceKey,
+		},
 	}
 	for _, o := range opts {
 		o(&c)
@@ -55,3 +92,157 @@ func WithIgnoreKeys(keys ...string) Option {
 		}
 	}
 }
+
+// WithTimeKey sets the key holding the record time. Defaults to "time".
+func WithTimeKey(key string) Option {
+	return func(c *config) {
+		c.keys.time = key
+	}
+}
+
+// WithLevelKey sets the key holding the record level. Defaults to "level".
+func WithLevelKey(key string) Option {
+	return func(c *config) {
+		c.keys.level = key
+	}
+}
+
+// WithMessageKey sets the key holding the record message. Defaults to "msg".
+func WithMessageKey(key string) Option {
+	return func(c *config) {
+		c.keys.message = key
+	}
+}
+
+// WithSourceKey sets the key holding the source location. Defaults to "source".
+func WithSourceKey(key string) Option {
+	return func(c *config) {
+		c.keys.source = key
+	}
+}
+
+// WithFormat sets the logger dialect to parse. Defaults to [FormatAuto], which
+// recognizes slog, zap, zerolog and logrus lines. Keys set with [WithTimeKey],
+// [WithLevelKey], [WithMessageKey] and [WithSourceKey] apply to [FormatSlog]
+// and [FormatAuto].
+func WithFormat(f Format) Option {
+	return func(c *config) {
+		c.format = f
+	}
+}
+
+// WithTimeLayouts adds Go time layouts used to parse record times that are not
+// RFC 3339. Layouts without a zone are read in the local time zone. Numeric
+// Unix timestamps in seconds, milliseconds, microseconds or nanoseconds are
+// always recognized.
+func WithTimeLayouts(layouts ...string) Option {
+	return func(c *config) {
+		c.timeLayouts = append(c.timeLayouts, layouts...)
+	}
+}
+
+// WithSortKeys sorts the keys of nested objects alphabetically instead of
+// keeping the order they were logged in.
+func WithSortKeys() Option {
+	return func(c *config) {
+		c.sortKeys = true
+	}
+}
+
+// WithMinLevel makes a [Scanner] drop records below level. Records whose level
+// is not recognized, and lines that are not records, are always written.
+// [Handler] filters with [HandlerOptions.Level] instead.
+func WithMinLevel(level slog.Leveler) Option {
+	return func(c *config) {
+		c.minLevel = level
+	}
+}
+
+// WithExplain makes a [Scanner] follow each line it passes through unchanged
+// with a note saying why the line was not read as a record, which helps to
+// track down malformed log emitters.
+func WithExplain() Option {
+	return func(c *config) {
+		c.explain = true
+	}
+}
+
+// WithJournald reads lines as `journalctl -o json` entries. The record in each
+// entry's MESSAGE is formatted, falling back to MESSAGE as plain text with
+// PRIORITY as the level, and the entry's _SYSTEMD_UNIT is shown as a label.
+func WithJournald() Option {
+	return func(c *config) {
+		c.journald = true
+	}
+}
+
+// WithEnvelope reads records nested in an export envelope at the dotted path,
+// such as "jsonPayload" or "data.record". The value at the path may be an
+// object or a string holding a JSON object. Lines without the path are read
+// as records themselves.
+func WithEnvelope(path string) Option {
+	return func(c *config) {
+		c.envelope = nil
+		if path != "" {
+			c.envelope = strings.Split(path, ".")
+		}
+	}
+}
+
+// WithKeepEnvelope keeps the fields of the envelope around a record, set with
+// [WithEnvelope], as attrs after the record's own.
+func WithKeepEnvelope() Option {
+	return func(c *config) {
+		c.keepEnvelope = true
+	}
+}
+
+// WithMaxLineSize sets the longest line, in bytes, a [Scanner] reads in full
+// (1MB by default). JSON records on longer lines are formatted from their
+// first n bytes, with large values shortened; other lines that long are
+// replaced by a warning.
+func WithMaxLineSize(n int) Option {
+	return func(c *config) {
+		if n > 0 {
+			c.maxLineSize = n
+		}
+	}
+}
+
+// WithExpandJSON renders string values that hold a JSON object or array, such
+// as request bodies, as nested values marked "(json)" instead of as escaped
+// text.
+func WithExpandJSON() Option {
+	return func(c *config) {
+		c.expandJSON = true
+	}
+}
+
+// WithCompact writes each record on a single line, with attrs following the
+// message as key=value pairs. Nested objects are flattened to dotted keys
+// (a.b=value), and values holding spaces, quotes or newlines are quoted.
+func WithCompact() Option {
+	return func(c *config) {
+		c.compact = true
+	}
+}
+
+// WithArrayWidth sets how wide, in bytes, an array of scalars may be to stay
+// inline as JSON (60 by default). Longer arrays, and arrays holding objects or
+// arrays, are written as lists of [index]=value lines. A width of 0 writes
+// every non-empty array as a list. Compact output keeps arrays inline.
+func WithArrayWidth(n int) Option {
+	return func(c *config) {
+		if n >= 0 {
+			c.arrayWidth = n
+		}
+	}
+}
+
+// WithTheme sets the colors of the output. Defaults to [DarkTheme]; see also
+// [LightTheme] and [HighContrastTheme].
+func WithTheme(t Theme) Option {
+	return func(c *config) {
+		c.theme = t
+	}
+}
@@ -3,139 +3,419 @@ package spretty
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 )
 
-// Parse attempts to parse a JSON line into a Record.
-// Returns (record, true) on success, or (nil, false) if the line is not valid
-// JSON or does not contain the expected slog fields.
-func Parse(line []byte) (*Record, bool) {
-	trimmed := bytes.TrimLeft(line, " \t\r\n")
-	if len(trimmed) == 0 || trimmed[0] != '{' {
-		return nil, false
+var defaultParser = NewParser() //nolint:gochecknoglobals // stateless default
+
+// defaultTimeLayouts are always tried before user-supplied layouts.
+var defaultTimeLayouts = []string{ //nolint:gochecknoglobals // constant lookup table
+	time.RFC3339Nano,
+	time.RFC3339,
+}
+
+// Parser parses JSON and logfmt log lines into Records.
+type Parser struct {
+	cfg     config
+	dialect dialect
+}
+
+// NewParser creates a Parser with the given options.
+func NewParser(opts ...Option) *Parser {
+	return newParser(newConfig(opts))
+}
+
+func newParser(cfg config) *Parser {
+	return &Parser{cfg: cfg, dialect: newDialect(cfg.format, cfg.keys)}
+}
+
+// Parse attempts to parse a JSON or logfmt line into a Record, recognizing
+// slog, zap, zerolog and logrus JSON lines and slog TextHandler lines.
+// If the line is neither or does not contain the expected fields, it returns
+// a [*ParseError] saying why.
+func Parse(line []byte) (*Record, error) {
+	return defaultParser.Parse(line)
+}
+
+// ParseInto is like [Parse] but fills rec, reusing its Attrs and Labels
+// slices. See [Parser.ParseInto].
+func ParseInto(line []byte, rec *Record) error {
+	return defaultParser.ParseInto(line, rec)
+}
+
+// slot holds the value claimed by a core field while a line is decoded.
+// String values are kept in str rather than boxed in val, which saves an
+// allocation for the fields nearly every line has.
+type slot struct {
+	set   bool
+	key   string
+	val   any
+	str   string
+	isStr bool
+	rank  int
+	pos   int // index in Attrs the value would occupy as an attr
+	off   int // byte offset of the value in the line
+}
+
+// value returns the slot's value as an attr value.
+func (s *slot) value() any {
+	if s.isStr {
+		return s.str
 	}
+	return s.val
+}
 
-	dec := json.NewDecoder(bytes.NewReader(trimmed))
-	dec.UseNumber()
+type fieldSlots [numFields]slot
 
-	tok, err := dec.Token()
-	if err != nil {
-		return nil, false
+// Parse attempts to parse a JSON or logfmt line into a Record.
+// If the line is neither or does not contain the configured core fields, it
+// returns a [*ParseError] saying why.
+func (p *Parser) Parse(line []byte) (*Record, error) {
+	var rec Record
+	if err := p.ParseInto(line, &rec); err != nil {
+		return nil, err
 	}
-	if _, ok := tok.(json.Delim); !ok {
-		return nil, false
+	return &rec, nil
+}
+
+// ParseInto is like [Parser.Parse] but fills rec instead of allocating a new
+// Record. rec is reset first, so its Attrs and Labels slices are reused; on
+// error its contents are unspecified. Strings and numbers without escapes
+// share a single copy of line, so a JSON line costs one allocation for that
+// copy plus one for each attr value. If line is not a log record, it returns
+// a [*ParseError], which matches [ErrNotRecord].
+func (p *Parser) ParseInto(line []byte, rec *Record) error {
+	var d decoder
+	return p.parseInto(line, rec, &d)
+}
+
+// parseInto parses line into rec, reusing d's scratch space.
+func (p *Parser) parseInto(line []byte, rec *Record, d *decoder) error {
+	if p.cfg.journald {
+		return p.parseJournald(line, rec, d)
 	}
+	return p.parseLine(line, rec, d)
+}
 
-	var rec Record
-	for dec.More() {
-		keyTok, err := dec.Token()
-		if err != nil {
-			return nil, false
-		}
-		key, ok := keyTok.(string)
-		if !ok {
-			return nil, false
-		}
+// parseLine parses a JSON or logfmt record.
+func (p *Parser) parseLine(line []byte, rec *Record, d *decoder) error {
+	rec.Reset()
+
+	start := len(line) - len(bytes.TrimLeft(line, " \t\r\n"))
+	if start == len(line) {
+		return parseErrorf(-1, "empty line")
+	}
+	if line[start] != '{' {
+		return p.parseLogfmt(line, start, rec)
+	}
+	if len(p.cfg.envelope) > 0 {
+		return p.parseEnvelope(line, start, rec, d)
+	}
 
-		switch key {
-		case "time":
-			rec.Time, err = parseTime(dec)
+	// Decode the whole line so that error offsets count from its start.
+	d.reset(line)
+	d.pos = start + 1 // opening brace
+
+	var slots fieldSlots
+	if d.peek() == '}' {
+		d.pos++
+	} else {
+		for {
+			kb, err := d.keyBytes()
 			if err != nil {
-				return nil, false
+				return err
 			}
-		case "level":
-			rec.Level, ok = decodeString(dec)
-			if !ok {
-				return nil, false
+			f, key, rank, core := lookupKey(&p.dialect, kb)
+			if !core {
+				key = d.intern(kb)
 			}
-		case "msg":
-			rec.Message, ok = decodeString(dec)
-			if !ok {
-				return nil, false
+			d.skipSpace()
+			sl := slot{key: key, off: d.pos}
+			if core && d.peek() == '"' {
+				b, err := d.stringBytes()
+				if err != nil {
+					return err
+				}
+				sl.isStr = true
+				if f == fieldLevel {
+					sl.str = d.intern(b)
+				} else {
+					sl.str = d.str(b)
+				}
+			} else if sl.val, err = d.value(); err != nil {
+				return err
 			}
-		case "source":
-			rec.Source, err = parseSource(dec)
-			if err != nil {
-				return nil, false
+
+			if !core || !p.claim(rec, &slots, f, rank, sl) {
+				rec.Attrs = append(rec.Attrs, Attr{Key: key, Value: sl.value()})
 			}
-		default:
-			val, err := decodeValue(dec)
+
+			more, err := d.more('}')
 			if err != nil {
-				return nil, false
+				return err
+			}
+			if !more {
+				break
 			}
-			rec.Attrs = append(rec.Attrs, Attr{Key: key, Value: val})
 		}
 	}
 
-	if rec.Time.IsZero() && rec.Level == "" && rec.Message == "" {
-		return nil, false
+	// Reject trailing garbage after the JSON object.
+	if d.peek() != 0 {
+		return d.errorf("unexpected %q after JSON object", d.data[d.pos])
 	}
 
-	// Consume closing delimiter.
-	if _, err := dec.Token(); err != nil {
-		return nil, false
+	return p.finishRecord(rec, &slots)
+}
+
+// claim offers the key and value in sl to the slot of core field f, where
+// rank is the key's rank among the field's candidates. It reports false if a
+// preferred key already holds the field, in which case the caller keeps the
+// pair as an attr.
+func (p *Parser) claim(rec *Record, slots *fieldSlots, f field, rank int, sl slot) bool {
+	if slots[f].set && slots[f].rank <= rank {
+		return false
+	}
+	if slots[f].set {
+		// A preferred key displaces an alias seen earlier; keep the alias as an attr.
+		restoreAttr(rec, slots, f)
 	}
+	sl.set, sl.rank, sl.pos = true, rank, len(rec.Attrs)
+	slots[f] = sl
+	return true
+}
 
-	// Reject trailing garbage after the JSON object.
-	if dec.More() {
-		return nil, false
+// restoreAttr puts the pair held by the slot of field f back among rec's
+// attrs, at the position it was logged in.
+func restoreAttr(rec *Record, slots *fieldSlots, f field) {
+	s := &slots[f]
+	rec.Attrs = slices.Insert(rec.Attrs, s.pos, Attr{Key: s.key, Value: s.value()})
+	for i := range slots {
+		if slots[i].set && field(i) != f && slots[i].pos >= s.pos {
+			slots[i].pos++
+		}
 	}
+}
 
-	return &rec, true
+// finishRecord assigns the claimed core fields and checks that rec looks like
+// a log record.
+func (p *Parser) finishRecord(rec *Record, slots *fieldSlots) error {
+	if err := p.assignFields(rec, slots); err != nil {
+		return err
+	}
+	if rec.Time.IsZero() && rec.Level == "" && rec.Message == "" {
+		return parseErrorf(-1, "no time, level or message field")
+	}
+	return nil
 }
 
-func parseTime(dec *json.Decoder) (time.Time, error) {
-	var s string
-	if err := dec.Decode(&s); err != nil {
-		return time.Time{}, fmt.Errorf("decoding time: %w", err)
+func (p *Parser) assignFields(rec *Record, slots *fieldSlots) error {
+	var err error
+	if s := &slots[fieldTime]; s.set {
+		if s.isStr {
+			rec.Time, err = p.parseTimeString(s.str)
+		} else {
+			rec.Time, err = p.parseTime(s.val)
+		}
+		if err != nil {
+			// An unreadable time is still worth showing; keep it as an attr.
+			restoreAttr(rec, slots, fieldTime)
+		}
+	}
+	if s := &slots[fieldLevel]; s.set {
+		if s.isStr {
+			rec.Level = normalizeLevel(s.str)
+		} else if rec.Level, err = parseLevel(s.val); err != nil {
+			// Like an unreadable time, an unreadable level is kept as an attr.
+			restoreAttr(rec, slots, fieldLevel)
+		}
+	}
+	if s := &slots[fieldMessage]; s.set {
+		if !s.isStr {
+			return parseErrorf(s.off, "%s: %s is not a string", s.key, jsonType(s.val))
+		}
+		rec.Message = s.str
 	}
+	if s := &slots[fieldSource]; s.set {
+		if str, ok := s.value().(string); ok {
+			if rec.Source, err = parseCaller(str); err != nil {
+				// A source string in some other form is still worth showing.
+				restoreAttr(rec, slots, fieldSource)
+			}
+		} else if rec.Source, err = parseSource(s.val); err != nil {
+			if _, ok := s.val.(Group); !ok {
+				return parseErrorf(s.off, "%s: %v", s.key, err)
+			}
+			// An object without a file or line is some other kind of caller.
+			restoreAttr(rec, slots, fieldSource)
+		}
+	}
+	if s := &slots[fieldFunction]; s.set {
+		if !s.isStr {
+			return parseErrorf(s.off, "%s: %s is not a string", s.key, jsonType(s.val))
+		}
+		if rec.Source == nil {
+			rec.Source = &Source{}
+		}
+		rec.Source.Function = s.str
+	}
+	return nil
+}
 
-	formats := []string{
-		time.RFC3339Nano,
-		time.RFC3339,
+func (p *Parser) parseTime(v any) (time.Time, error) {
+	switch v := v.(type) {
+	case string:
+		return p.parseTimeString(v)
+	case json.Number:
+		return parseEpoch(v.String())
+	default:
+		return time.Time{}, fmt.Errorf("unsupported time value: %T", v)
 	}
-	for _, f := range formats {
+}
+
+func (p *Parser) parseTimeString(s string) (time.Time, error) {
+	for _, f := range defaultTimeLayouts {
 		if t, err := time.Parse(f, s); err == nil {
 			return t, nil
 		}
 	}
+	for _, f := range p.cfg.timeLayouts {
+		if t, err := time.ParseInLocation(f, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	if isDecimal(s) {
+		return parseEpoch(s)
+	}
 	return time.Time{}, fmt.Errorf("unsupported time format: %s", s)
 }
 
-func parseSource(dec *json.Decoder) (*Source, error) {
-	var raw json.RawMessage
-	if err := dec.Decode(&raw); err != nil {
-		return nil, fmt.Errorf("decoding source: %w", err)
+// parseEpoch parses a decimal Unix timestamp, detecting from its magnitude
+// whether it counts seconds, milliseconds, microseconds or nanoseconds.
+// Fractions are kept without float rounding.
+func parseEpoch(s string) (time.Time, error) {
+	whole, frac, _ := strings.Cut(s, ".")
+	n, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("parsing epoch: %w", err)
 	}
 
-	var src Source
-	if err := json.Unmarshal(raw, &src); err != nil {
-		return nil, fmt.Errorf("unmarshalling source: %w", err)
+	abs := n
+	if abs < 0 {
+		abs = -abs
 	}
-	return &src, nil
+	var unit int64
+	switch {
+	case abs < 1e11:
+		unit = int64(time.Second)
+	case abs < 1e14:
+		unit = int64(time.Millisecond)
+	case abs < 1e17:
+		unit = int64(time.Microsecond)
+	default:
+		unit = int64(time.Nanosecond)
+	}
+
+	var fracNanos int64
+	if frac != "" {
+		// Scale the fraction to nanoseconds of the detected unit.
+		digits := len(strconv.FormatInt(unit, 10)) - 1
+		frac = (frac + "000000000")[:digits]
+		if frac != "" {
+			if fracNanos, err = strconv.ParseInt(frac, 10, 64); err != nil {
+				return time.Time{}, fmt.Errorf("parsing epoch fraction: %w", err)
+			}
+		}
+		if s[0] == '-' {
+			// Take the sign from s, since n is 0 for "-0.5".
+			fracNanos = -fracNanos
+		}
+	}
+
+	sec, rem := n/(int64(time.Second)/unit), n%(int64(time.Second)/unit)
+	return time.Unix(sec, rem*unit+fracNanos).UTC(), nil
 }
 
-func decodeString(dec *json.Decoder) (string, bool) {
-	var s string
-	if err := dec.Decode(&s); err != nil {
-		return "", false
+func isDecimal(s string) bool {
+	if s == "" {
+		return false
 	}
-	return s, true
+	dot := false
+	for i, c := range s {
+		switch {
+		case c >= '0' && c <= '9':
+		case c == '.' && !dot && i > 0:
+			dot = true
+		default:
+			return false
+		}
+	}
+	return true
 }
 
-func decodeValue(dec *json.Decoder) (any, error) {
-	var raw json.RawMessage
-	if err := dec.Decode(&raw); err != nil {
-		return nil, fmt.Errorf("decoding value: %w", err)
+// parseSource parses a source object such as slog's
+// {"function":...,"file":...,"line":...}.
+func parseSource(v any) (*Source, error) {
+	g, ok := v.(Group)
+	if !ok {
+		return nil, fmt.Errorf("%s is not an object or string", jsonType(v))
+	}
+
+	var src Source
+	located := false
+	for _, a := range g {
+		k, fv := a.Key, a.Value
+		switch k {
+		case "function":
+			src.Function, ok = fv.(string)
+		case "file":
+			src.File, ok = fv.(string)
+			located = true
+		case "line":
+			located = true
+			var n json.Number
+			if n, ok = fv.(json.Number); ok {
+				line, err := strconv.Atoi(n.String())
+				if err != nil {
+					return nil, fmt.Errorf("parsing source line: %w", err)
+				}
+				src.Line = line
+			}
+		default:
+			ok = true
+		}
+		if !ok {
+			return nil, fmt.Errorf("%s is %s", k, jsonType(fv))
+		}
+	}
+	if !located {
+		return nil, errors.New("no file or line")
 	}
+	return &src, nil
+}
 
-	d := json.NewDecoder(bytes.NewReader(raw))
-	d.UseNumber()
+// parseCaller parses a "file:line" or "function file:line" caller string,
+// as written by zap, zerolog and handlers that flatten slog's source.
+func parseCaller(s string) (*Source, error) {
+	var src Source
+	s = strings.TrimSpace(s)
+	if fn, file, ok := strings.Cut(s, " "); ok {
+		src.Function, s = fn, strings.TrimSpace(file)
+	}
 
-	var v any
-	if err := d.Decode(&v); err != nil {
-		return nil, fmt.Errorf("unmarshalling value: %w", err)
+	i := strings.LastIndexByte(s, ':')
+	if i <= 0 {
+		return nil, fmt.Errorf("caller has no line number: %s", s)
+	}
+	line, err := strconv.Atoi(s[i+1:])
+	if err != nil || line < 0 {
+		return nil, fmt.Errorf("invalid caller line: %s", s[i+1:])
 	}
-	return v, nil
+	src.File, src.Line = s[:i], line
+	return &src, nil
 }
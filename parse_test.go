@@ -1,6 +1,10 @@
 package spretty_test
 
 import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -88,12 +92,37 @@ func TestParse(t *testing.T) {
 				if len(r.Attrs) != 1 {
 					t.Fatalf("Attrs length = %d, want 1", len(r.Attrs))
 				}
-				params, ok := r.Attrs[0].Value.(map[string]any)
+				params, ok := r.Attrs[0].Value.(spretty.Group)
 				if !ok {
-					t.Fatalf("Attrs[0].Value type = %T, want map[string]any", r.Attrs[0].Value)
+					t.Fatalf("Attrs[0].Value type = %T, want spretty.Group", r.Attrs[0].Value)
 				}
-				if params["table"] != "users" {
-					t.Errorf("params[table] = %v, want %q", params["table"], "users")
+				if len(params) != 2 {
+					t.Fatalf("params length = %d, want 2", len(params))
+				}
+				if params[0].Key != "table" || params[0].Value != "users" {
+					t.Errorf("params[0] = %v, want table=users", params[0])
+				}
+				if params[1].Key != "limit" {
+					t.Errorf("params[1].Key = %q, want %q", params[1].Key, "limit")
+				}
+			},
+		},
+		{
+			name:   "preserves nested key order",
+			input:  `{"level":"INFO","msg":"req","user":{"z":1,"a":{"y":true,"b":null}},"tags":[{"k":"v","a":"b"}]}`,
+			wantOK: true,
+			check: func(t *testing.T, r *spretty.Record) {
+				t.Helper()
+				want := spretty.Group{
+					{Key: "z", Value: json.Number("1")},
+					{Key: "a", Value: spretty.Group{{Key: "y", Value: true}, {Key: "b", Value: nil}}},
+				}
+				if !reflect.DeepEqual(r.Attrs[0].Value, want) {
+					t.Errorf("user = %#v, want %#v", r.Attrs[0].Value, want)
+				}
+				wantTags := []any{spretty.Group{{Key: "k", Value: "v"}, {Key: "a", Value: "b"}}}
+				if !reflect.DeepEqual(r.Attrs[1].Value, wantTags) {
+					t.Errorf("tags = %#v, want %#v", r.Attrs[1].Value, wantTags)
 				}
 			},
 		},
@@ -108,6 +137,289 @@ func TestParse(t *testing.T) {
 				}
 			},
 		},
+		{
+			name:   "zerolog line is recognized",
+			input:  `{"level":"error","time":"2026-02-26T10:15:30Z","message":"boom","caller":"/app/db.go:12"}`,
+			wantOK: true,
+			check: func(t *testing.T, r *spretty.Record) {
+				t.Helper()
+				if r.Level != "ERROR" {
+					t.Errorf("Level = %q, want %q", r.Level, "ERROR")
+				}
+				if r.Message != "boom" {
+					t.Errorf("Message = %q, want %q", r.Message, "boom")
+				}
+				if r.Source == nil || r.Source.File != "/app/db.go" || r.Source.Line != 12 {
+					t.Errorf("Source = %+v, want /app/db.go:12", r.Source)
+				}
+			},
+		},
+		{
+			name:   "string source",
+			input:  `{"level":"INFO","msg":"hi","source":"main.go:10"}`,
+			wantOK: true,
+			check: func(t *testing.T, r *spretty.Record) {
+				t.Helper()
+				want := spretty.Source{File: "main.go", Line: 10}
+				if r.Source == nil || *r.Source != want {
+					t.Errorf("Source = %+v, want %+v", r.Source, want)
+				}
+			},
+		},
+		{
+			name:   "string source with function",
+			input:  `{"level":"INFO","msg":"hi","source":"main.run /app/main.go:42"}`,
+			wantOK: true,
+			check: func(t *testing.T, r *spretty.Record) {
+				t.Helper()
+				want := spretty.Source{Function: "main.run", File: "/app/main.go", Line: 42}
+				if r.Source == nil || *r.Source != want {
+					t.Errorf("Source = %+v, want %+v", r.Source, want)
+				}
+			},
+		},
+		{
+			name:   "string source without line is kept as attr",
+			input:  `{"level":"INFO","source":"db","msg":"hi","n":1}`,
+			wantOK: true,
+			check: func(t *testing.T, r *spretty.Record) {
+				t.Helper()
+				if r.Source != nil {
+					t.Errorf("Source = %+v, want nil", r.Source)
+				}
+				want := []spretty.Attr{{Key: "source", Value: "db"}, {Key: "n", Value: json.Number("1")}}
+				if !reflect.DeepEqual(r.Attrs, want) {
+					t.Errorf("Attrs = %v, want %v", r.Attrs, want)
+				}
+			},
+		},
+		{
+			name:   "caller object without file or line is kept as attr",
+			input:  `{"level":"INFO","msg":"hi","caller":{"name":"alice","phone":"555"}}`,
+			wantOK: true,
+			check: func(t *testing.T, r *spretty.Record) {
+				t.Helper()
+				if r.Source != nil {
+					t.Errorf("Source = %+v, want nil", r.Source)
+				}
+				want := []spretty.Attr{{Key: "caller", Value: spretty.Group{
+					{Key: "name", Value: "alice"}, {Key: "phone", Value: "555"},
+				}}}
+				if !reflect.DeepEqual(r.Attrs, want) {
+					t.Errorf("Attrs = %v, want %v", r.Attrs, want)
+				}
+			},
+		},
+		{
+			name:   "epoch seconds",
+			input:  `{"time":1772100930,"level":"INFO","msg":"tick"}`,
+			wantOK: true,
+			check:  checkTime(time.Date(2026, 2, 26, 10, 15, 30, 0, time.UTC)),
+		},
+		{
+			name:   "epoch milliseconds",
+			input:  `{"time":1772100930123,"level":"INFO","msg":"tick"}`,
+			wantOK: true,
+			check:  checkTime(time.Date(2026, 2, 26, 10, 15, 30, 123000000, time.UTC)),
+		},
+		{
+			name:   "epoch microseconds with fraction",
+			input:  `{"time":1772100930123456.7,"level":"INFO","msg":"tick"}`,
+			wantOK: true,
+			check:  checkTime(time.Date(2026, 2, 26, 10, 15, 30, 123456700, time.UTC)),
+		},
+		{
+			name:   "epoch nanoseconds",
+			input:  `{"time":1772100930123456789,"level":"INFO","msg":"tick"}`,
+			wantOK: true,
+			check:  checkTime(time.Date(2026, 2, 26, 10, 15, 30, 123456789, time.UTC)),
+		},
+		{
+			name:   "negative epoch under a second",
+			input:  `{"time":-0.5,"level":"INFO","msg":"tick"}`,
+			wantOK: true,
+			check:  checkTime(time.Date(1969, 12, 31, 23, 59, 59, 500000000, time.UTC)),
+		},
+		{
+			name:   "epoch as string",
+			input:  `{"time":"1772100930.5","level":"INFO","msg":"tick"}`,
+			wantOK: true,
+			check:  checkTime(time.Date(2026, 2, 26, 10, 15, 30, 500000000, time.UTC)),
+		},
+		{
+			name:   "unparseable time becomes attr",
+			input:  `{"level":"INFO","time":"yesterday","msg":"tick","n":1}`,
+			wantOK: true,
+			check: func(t *testing.T, r *spretty.Record) {
+				t.Helper()
+				if !r.Time.IsZero() {
+					t.Errorf("Time = %v, want zero", r.Time)
+				}
+				if len(r.Attrs) != 2 || r.Attrs[0].Key != "time" || r.Attrs[0].Value != "yesterday" {
+					t.Errorf("Attrs = %v, want [time=yesterday n=1]", r.Attrs)
+				}
+			},
+		},
+		{
+			name:   "unparseable time alone is not a record",
+			input:  `{"time":"yesterday"}`,
+			wantOK: false,
+		},
+		{
+			name:   "slog level offset",
+			input:  `{"level":"INFO+2","msg":"tick"}`,
+			wantOK: true,
+			check:  checkLevel("INFO+2"),
+		},
+		{
+			name:   "numeric slog level",
+			input:  `{"level":8,"msg":"tick"}`,
+			wantOK: true,
+			check:  checkLevel("ERROR"),
+		},
+		{
+			name:   "numeric slog level with offset",
+			input:  `{"level":-2,"msg":"tick"}`,
+			wantOK: true,
+			check:  checkLevel("DEBUG+2"),
+		},
+		{
+			name:   "pino level",
+			input:  `{"level":40,"msg":"tick"}`,
+			wantOK: true,
+			check:  checkLevel("WARN"),
+		},
+		{
+			name:   "pino fatal level",
+			input:  `{"level":60,"msg":"tick"}`,
+			wantOK: true,
+			check:  checkLevel("FATAL"),
+		},
+		{
+			name:   "integral float level",
+			input:  `{"level":30.0,"msg":"tick"}`,
+			wantOK: true,
+			check:  checkLevel("INFO"),
+		},
+		{
+			name:   "fractional level becomes attr",
+			input:  `{"level":1.5,"msg":"tick"}`,
+			wantOK: true,
+			check:  checkLevelAttr(json.Number("1.5")),
+		},
+		{
+			name:   "null level becomes attr",
+			input:  `{"level":null,"msg":"tick"}`,
+			wantOK: true,
+			check:  checkLevelAttr(nil),
+		},
+		{
+			name:   "object level becomes attr",
+			input:  `{"level":{"name":"INFO"},"msg":"tick"}`,
+			wantOK: true,
+			check:  checkLevelAttr(spretty.Group{{Key: "name", Value: "INFO"}}),
+		},
+		{
+			name:   "unreadable level alone is not a record",
+			input:  `{"level":null}`,
+			wantOK: false,
+		},
+		{
+			name:   "string escapes",
+			input:  `{"level":"INFO","msg":"a\"b\\c\/d\b\f\n\r\t","u":"\u00e9\ud83d\ude00","lone":"\ud83d!"}`,
+			wantOK: true,
+			check: func(t *testing.T, r *spretty.Record) {
+				t.Helper()
+				if want := "a\"b\\c/d\b\f\n\r\t"; r.Message != want {
+					t.Errorf("Message = %q, want %q", r.Message, want)
+				}
+				if want := "é😀"; r.Attrs[0].Value != want {
+					t.Errorf("u = %q, want %q", r.Attrs[0].Value, want)
+				}
+				if want := "\ufffd!"; r.Attrs[1].Value != want {
+					t.Errorf("lone = %q, want %q", r.Attrs[1].Value, want)
+				}
+			},
+		},
+		{
+			name:   "invalid UTF-8 is replaced",
+			input:  "{\"level\":\"INFO\",\"msg\":\"bad \xff byte\"}",
+			wantOK: true,
+			check: func(t *testing.T, r *spretty.Record) {
+				t.Helper()
+				if want := "bad \ufffd byte"; r.Message != want {
+					t.Errorf("Message = %q, want %q", r.Message, want)
+				}
+			},
+		},
+		{
+			name:   "numbers and literals",
+			input:  `{"level":"INFO","msg":"n","a":-0.5e+3,"b":0,"c":true,"d":false,"e":null,"f":[],"g":{}}`,
+			wantOK: true,
+			check: func(t *testing.T, r *spretty.Record) {
+				t.Helper()
+				want := []any{json.Number("-0.5e+3"), json.Number("0"), true, false, nil, []any{}, spretty.Group{}}
+				if len(r.Attrs) != len(want) {
+					t.Fatalf("Attrs length = %d, want %d", len(r.Attrs), len(want))
+				}
+				for i, w := range want {
+					if !reflect.DeepEqual(r.Attrs[i].Value, w) {
+						t.Errorf("Attrs[%d].Value = %#v, want %#v", i, r.Attrs[i].Value, w)
+					}
+				}
+			},
+		},
+		{
+			name:   "whitespace between tokens",
+			input:  "{ \"level\" : \"INFO\" ,\t\"msg\" :\"hi\" , \"a\" : [ 1 , { \"b\" : 2 } ] }\r\n",
+			wantOK: true,
+			check:  checkLevel("INFO"),
+		},
+		{
+			name:   "leading zero",
+			input:  `{"level":"INFO","n":01}`,
+			wantOK: false,
+		},
+		{
+			name:   "bad literal",
+			input:  `{"level":"INFO","ok":tru}`,
+			wantOK: false,
+		},
+		{
+			name:   "raw control character in string",
+			input:  "{\"level\":\"INFO\",\"msg\":\"a\tb\"}",
+			wantOK: false,
+		},
+		{
+			name:   "invalid escape",
+			input:  `{"level":"INFO","msg":"\x"}`,
+			wantOK: false,
+		},
+		{
+			name:   "trailing comma",
+			input:  `{"level":"INFO","msg":"hi",}`,
+			wantOK: false,
+		},
+		{
+			name:   "missing colon",
+			input:  `{"level" "INFO"}`,
+			wantOK: false,
+		},
+		{
+			name:   "unterminated nested array",
+			input:  `{"level":"INFO","a":[1,2}`,
+			wantOK: false,
+		},
+		{
+			name:   "nesting too deep",
+			input:  `{"level":"INFO","a":` + strings.Repeat("[", 10001) + strings.Repeat("]", 10001) + `}`,
+			wantOK: false,
+		},
+		{
+			name:   "two objects",
+			input:  `{"level":"INFO"}{"level":"WARN"}`,
+			wantOK: false,
+		},
 		{
 			name:   "not JSON",
 			input:  "plain text line",
@@ -183,9 +495,204 @@ func TestParse(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
 
-			rec, ok := spretty.Parse([]byte(tt.input))
-			if ok != tt.wantOK {
-				t.Fatalf("Parse() ok = %v, want %v", ok, tt.wantOK)
+			rec, err := spretty.Parse([]byte(tt.input))
+			if ok := err == nil; ok != tt.wantOK {
+				t.Fatalf("Parse() error = %v, wantOK %v", err, tt.wantOK)
+			}
+			if tt.check != nil && rec != nil {
+				tt.check(t, rec)
+			}
+		})
+	}
+}
+
+func TestParser_Parse(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		opts   []spretty.Option
+		input  string
+		wantOK bool
+		check  func(t *testing.T, r *spretty.Record)
+	}{
+		{
+			name: "renamed core keys",
+			opts: []spretty.Option{
+				spretty.WithTimeKey("ts"),
+				spretty.WithLevelKey("severity"),
+				spretty.WithMessageKey("message"),
+				spretty.WithSourceKey("caller"),
+			},
+			input: `{"ts":"2026-02-26T10:15:30Z","severity":"WARN","message":"slow",` +
+				`"caller":{"function":"main.run","file":"/app/main.go","line":7},"took":"2s"}`,
+			wantOK: true,
+			check: func(t *testing.T, r *spretty.Record) {
+				t.Helper()
+				wantTime := time.Date(2026, 2, 26, 10, 15, 30, 0, time.UTC)
+				if !r.Time.Equal(wantTime) {
+					t.Errorf("Time = %v, want %v", r.Time, wantTime)
+				}
+				if r.Level != "WARN" {
+					t.Errorf("Level = %q, want %q", r.Level, "WARN")
+				}
+				if r.Message != "slow" {
+					t.Errorf("Message = %q, want %q", r.Message, "slow")
+				}
+				if r.Source == nil || r.Source.Line != 7 {
+					t.Errorf("Source = %v, want line 7", r.Source)
+				}
+				if len(r.Attrs) != 1 || r.Attrs[0].Key != "took" {
+					t.Errorf("Attrs = %v, want [took]", r.Attrs)
+				}
+			},
+		},
+		{
+			name:   "default keys become attrs when renamed",
+			opts:   []spretty.Option{spretty.WithMessageKey("message")},
+			input:  `{"level":"INFO","message":"hello","msg":"other"}`,
+			wantOK: true,
+			check: func(t *testing.T, r *spretty.Record) {
+				t.Helper()
+				if r.Message != "hello" {
+					t.Errorf("Message = %q, want %q", r.Message, "hello")
+				}
+				if len(r.Attrs) != 1 || r.Attrs[0].Key != "msg" {
+					t.Errorf("Attrs = %v, want [msg]", r.Attrs)
+				}
+			},
+		},
+		{
+			name:   "zap line",
+			opts:   []spretty.Option{spretty.WithFormat(spretty.FormatZap)},
+			input:  `{"level":"info","ts":1772100930.123456,"caller":"app/main.go:42","msg":"started","port":8080}`,
+			wantOK: true,
+			check: func(t *testing.T, r *spretty.Record) {
+				t.Helper()
+				wantTime := time.Date(2026, 2, 26, 10, 15, 30, 123456000, time.UTC)
+				if !r.Time.Equal(wantTime) {
+					t.Errorf("Time = %v, want %v", r.Time, wantTime)
+				}
+				if r.Level != "INFO" {
+					t.Errorf("Level = %q, want %q", r.Level, "INFO")
+				}
+				if r.Message != "started" {
+					t.Errorf("Message = %q, want %q", r.Message, "started")
+				}
+				if r.Source == nil || r.Source.File != "app/main.go" || r.Source.Line != 42 {
+					t.Errorf("Source = %+v, want app/main.go:42", r.Source)
+				}
+				if len(r.Attrs) != 1 || r.Attrs[0].Key != "port" {
+					t.Errorf("Attrs = %v, want [port]", r.Attrs)
+				}
+			},
+		},
+		{
+			name:   "zerolog line",
+			opts:   []spretty.Option{spretty.WithFormat(spretty.FormatZerolog)},
+			input:  `{"level":"warn","time":"2026-02-26T10:15:30Z","message":"slow"}`,
+			wantOK: true,
+			check: func(t *testing.T, r *spretty.Record) {
+				t.Helper()
+				if r.Level != "WARN" {
+					t.Errorf("Level = %q, want %q", r.Level, "WARN")
+				}
+				if r.Message != "slow" {
+					t.Errorf("Message = %q, want %q", r.Message, "slow")
+				}
+			},
+		},
+		{
+			name: "logrus line with caller",
+			opts: []spretty.Option{spretty.WithFormat(spretty.FormatLogrus)},
+			input: `{"file":"/app/main.go:10","func":"main.main","level":"warning",` +
+				`"msg":"disk low","time":"2026-02-26T10:15:30+09:00"}`,
+			wantOK: true,
+			check: func(t *testing.T, r *spretty.Record) {
+				t.Helper()
+				if r.Level != "WARN" {
+					t.Errorf("Level = %q, want %q", r.Level, "WARN")
+				}
+				want := spretty.Source{Function: "main.main", File: "/app/main.go", Line: 10}
+				if r.Source == nil || *r.Source != want {
+					t.Errorf("Source = %+v, want %+v", r.Source, want)
+				}
+				if len(r.Attrs) != 0 {
+					t.Errorf("Attrs = %v, want empty", r.Attrs)
+				}
+			},
+		},
+		{
+			name:   "slog format ignores aliases",
+			opts:   []spretty.Option{spretty.WithFormat(spretty.FormatSlog)},
+			input:  `{"ts":1772100930,"message":"hello"}`,
+			wantOK: false,
+		},
+		{
+			name:   "auto prefers slog keys over aliases",
+			input:  `{"message":"attr","time":"2026-02-26T10:15:30Z","level":"INFO","msg":"hello","ts":1}`,
+			wantOK: true,
+			check: func(t *testing.T, r *spretty.Record) {
+				t.Helper()
+				if r.Message != "hello" {
+					t.Errorf("Message = %q, want %q", r.Message, "hello")
+				}
+				wantKeys := []string{"message", "ts"}
+				if len(r.Attrs) != len(wantKeys) {
+					t.Fatalf("Attrs = %v, want keys %v", r.Attrs, wantKeys)
+				}
+				for i, wk := range wantKeys {
+					if r.Attrs[i].Key != wk {
+						t.Errorf("Attrs[%d].Key = %q, want %q", i, r.Attrs[i].Key, wk)
+					}
+				}
+			},
+		},
+		{
+			name:   "custom time layout",
+			opts:   []spretty.Option{spretty.WithTimeLayouts("2006-01-02 15:04:05 MST", "2006/01/02 15:04:05.000Z07:00")},
+			input:  `{"time":"2026/02/26 10:15:30.123Z","level":"INFO","msg":"hello"}`,
+			wantOK: true,
+			check: func(t *testing.T, r *spretty.Record) {
+				t.Helper()
+				wantTime := time.Date(2026, 2, 26, 10, 15, 30, 123000000, time.UTC)
+				if !r.Time.Equal(wantTime) {
+					t.Errorf("Time = %v, want %v", r.Time, wantTime)
+				}
+			},
+		},
+		{
+			name:   "zoneless layout uses local time",
+			opts:   []spretty.Option{spretty.WithTimeLayouts("2006-01-02 15:04:05")},
+			input:  `{"time":"2026-02-26 10:15:30","level":"INFO","msg":"hello"}`,
+			wantOK: true,
+			check: func(t *testing.T, r *spretty.Record) {
+				t.Helper()
+				wantTime := time.Date(2026, 2, 26, 10, 15, 30, 0, time.Local)
+				if !r.Time.Equal(wantTime) {
+					t.Errorf("Time = %v, want %v", r.Time, wantTime)
+				}
+			},
+		},
+		{
+			name: "renamed keys missing",
+			opts: []spretty.Option{
+				spretty.WithTimeKey("ts"),
+				spretty.WithLevelKey("severity"),
+				spretty.WithMessageKey("message"),
+			},
+			input:  `{"foo":"bar"}`,
+			wantOK: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			rec, err := spretty.NewParser(tt.opts...).Parse([]byte(tt.input))
+			if ok := err == nil; ok != tt.wantOK {
+				t.Fatalf("Parse() error = %v, wantOK %v", err, tt.wantOK)
 			}
 			if tt.check != nil && rec != nil {
 				tt.check(t, rec)
@@ -193,3 +700,235 @@ func TestParse(t *testing.T) {
 		})
 	}
 }
+
+func checkTime(want time.Time) func(t *testing.T, r *spretty.Record) {
+	return func(t *testing.T, r *spretty.Record) {
+		t.Helper()
+		if !r.Time.Equal(want) {
+			t.Errorf("Time = %v, want %v", r.Time, want)
+		}
+	}
+}
+
+func checkLevel(want string) func(t *testing.T, r *spretty.Record) {
+	return func(t *testing.T, r *spretty.Record) {
+		t.Helper()
+		if r.Level != want {
+			t.Errorf("Level = %q, want %q", r.Level, want)
+		}
+	}
+}
+
+func checkLevelAttr(want any) func(t *testing.T, r *spretty.Record) {
+	return func(t *testing.T, r *spretty.Record) {
+		t.Helper()
+		if r.Level != "" {
+			t.Errorf("Level = %q, want empty", r.Level)
+		}
+		if wantAttrs := []spretty.Attr{{Key: "level", Value: want}}; !reflect.DeepEqual(r.Attrs, wantAttrs) {
+			t.Errorf("Attrs = %v, want %v", r.Attrs, wantAttrs)
+		}
+	}
+}
+
+func BenchmarkParse(b *testing.B) {
+	benchmarks := []struct {
+		name string
+		line string
+	}{
+		{
+			name: "minimal",
+			line: `{"time":"2026-02-26T10:15:30.123Z","level":"INFO","msg":"hello"}`,
+		},
+		{
+			name: "typical",
+			line: `{"time":"2026-02-26T10:15:30.123456789+09:00","level":"INFO","msg":"request handled",` +
+				`"source":{"function":"main.handle","file":"/app/main.go","line":42},` +
+				`"request_id":"3f2a9c1e-7b4d-4e8a-9f1c-2d3e4f5a6b7c","method":"GET","path":"/api/users",` +
+				`"status":200,"latency_ms":12.5,"cached":false,"user":{"id":42,"name":"alice","roles":["admin","dev"]}}`,
+		},
+		{
+			name: "escaped",
+			line: `{"time":"2026-02-26T10:15:30Z","level":"ERROR","msg":"failed: \"quoted\"\n\ttab",` +
+				`"err":"open /tmp/été.txt: no such file","stack":"line1\nline2\nline3"}`,
+		},
+	}
+
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			line := []byte(bm.line)
+			b.ReportAllocs()
+			b.SetBytes(int64(len(line)))
+			for b.Loop() {
+				if _, err := spretty.Parse(line); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestParseInto(t *testing.T) {
+	t.Parallel()
+
+	var rec spretty.Record
+
+	first := `{"level":"INFO","msg":"first","a":1,"b":2,"c":3,"source":{"file":"a.go","line":1}}`
+	if err := spretty.ParseInto([]byte(first), &rec); err != nil {
+		t.Fatalf("ParseInto(first) error = %v", err)
+	}
+	if len(rec.Attrs) != 3 || rec.Source == nil {
+		t.Fatalf("first record = %+v", rec)
+	}
+	attrs := rec.Attrs
+
+	second := `{"time":"2026-02-26T10:15:30Z","level":"WARN","msg":"second","d":4}`
+	if err := spretty.ParseInto([]byte(second), &rec); err != nil {
+		t.Fatalf("ParseInto(second) error = %v", err)
+	}
+	if rec.Message != "second" || rec.Level != "WARN" || rec.Source != nil {
+		t.Errorf("second record = %+v", rec)
+	}
+	if len(rec.Attrs) != 1 || rec.Attrs[0].Key != "d" {
+		t.Errorf("Attrs = %v, want [d]", rec.Attrs)
+	}
+	if &rec.Attrs[0] != &attrs[0] {
+		t.Error("Attrs backing array was not reused")
+	}
+
+	if err := spretty.ParseInto([]byte("plain text"), &rec); !errors.Is(err, spretty.ErrNotRecord) {
+		t.Errorf("ParseInto(plain text) error = %v, want ErrNotRecord", err)
+	}
+}
+
+func TestParse_Error(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		input      string
+		wantReason string
+		wantOffset int
+	}{
+		{
+			name:       "empty line",
+			input:      "  ",
+			wantReason: "empty line",
+			wantOffset: -1,
+		},
+		{
+			name:       "plain text",
+			input:      "hello world",
+			wantReason: "expected key=value pair",
+			wantOffset: 0,
+		},
+		{
+			name:       "logfmt bare word after pairs",
+			input:      `level=INFO msg="hi" oops`,
+			wantReason: "expected key=value pair",
+			wantOffset: 20,
+		},
+		{
+			name:       "logfmt unterminated quote",
+			input:      `level=INFO msg="hi`,
+			wantReason: "unterminated quoted string",
+			wantOffset: 15,
+		},
+		{
+			name:       "malformed JSON",
+			input:      `{"level":"INFO","msg":}`,
+			wantReason: `unexpected '}', expected value`,
+			wantOffset: 22,
+		},
+		{
+			name:       "malformed JSON after indentation",
+			input:      `  {"level" "INFO"}`,
+			wantReason: `unexpected '"', expected ':'`,
+			wantOffset: 11,
+		},
+		{
+			name:       "truncated JSON",
+			input:      `{"level":"INFO","msg":"hi`,
+			wantReason: "unexpected end of input in string",
+			wantOffset: 25,
+		},
+		{
+			name:       "trailing data",
+			input:      `{"level":"INFO","msg":"hi"} extra`,
+			wantReason: `unexpected 'e' after JSON object`,
+			wantOffset: 28,
+		},
+		{
+			name:       "non-string message",
+			input:      `{"level":"INFO","msg":42}`,
+			wantReason: "msg: a number is not a string",
+			wantOffset: 22,
+		},
+		{
+			name:       "bad source",
+			input:      `{"level":"INFO","msg":"hi","source":true}`,
+			wantReason: "source: a boolean is not an object or string",
+			wantOffset: 36,
+		},
+		{
+			name:       "no core fields",
+			input:      `{"foo":"bar"}`,
+			wantReason: "no time, level or message field",
+			wantOffset: -1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := spretty.Parse([]byte(tt.input))
+			var perr *spretty.ParseError
+			if !errors.As(err, &perr) {
+				t.Fatalf("Parse() error = %v, want *ParseError", err)
+			}
+			if perr.Reason != tt.wantReason {
+				t.Errorf("Reason = %q, want %q", perr.Reason, tt.wantReason)
+			}
+			if perr.Offset != tt.wantOffset {
+				t.Errorf("Offset = %d, want %d", perr.Offset, tt.wantOffset)
+			}
+			if !errors.Is(err, spretty.ErrNotRecord) {
+				t.Error("errors.Is(err, ErrNotRecord) = false, want true")
+			}
+		})
+	}
+}
+
+//nolint:paralleltest // AllocsPerRun cannot run in parallel tests
+func TestParser_ParseInto_Allocs(t *testing.T) {
+	line := []byte(`{"time":"2026-02-26T10:15:30.123Z","level":"INFO","msg":"request handled",` +
+		`"method":"GET","path":"/api/users","status":200}`)
+	p := spretty.NewParser()
+	var rec spretty.Record
+
+	// One copy of the line, plus one for each of the three attr values.
+	allocs := testing.AllocsPerRun(100, func() {
+		if err := p.ParseInto(line, &rec); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if allocs > 4 {
+		t.Errorf("ParseInto() allocs = %v, want at most 4", allocs)
+	}
+}
+
+func BenchmarkParser_ParseInto(b *testing.B) {
+	line := []byte(`{"time":"2026-02-26T10:15:30.123Z","level":"INFO","msg":"request handled",` +
+		`"method":"GET","path":"/api/users","status":200}`)
+	p := spretty.NewParser()
+	var rec spretty.Record
+
+	b.ReportAllocs()
+	b.SetBytes(int64(len(line)))
+	for b.Loop() {
+		if err := p.ParseInto(line, &rec); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -1,6 +1,11 @@
 package spretty
 
-import "time"
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+)
 
 // Record holds the parsed fields of a slog JSON log line.
 type Record struct {
@@ -9,6 +14,21 @@ type Record struct {
 	Message string
 	Source  *Source
 	Attrs   []Attr
+
+	// Labels are short tags shown in front of the record, such as the
+	// container name that prefixed the line.
+	Labels []string
+
+	// Stream is "stdout" or "stderr" for records a container runtime
+	// captured, and empty otherwise.
+	Stream string
+}
+
+// Reset clears r for reuse, keeping the capacity of its Attrs and Labels.
+func (r *Record) Reset() {
+	clear(r.Attrs)
+	clear(r.Labels)
+	*r = Record{Attrs: r.Attrs[:0], Labels: r.Labels[:0]}
 }
 
 // Source represents the slog source location.
@@ -19,7 +39,36 @@ type Source struct {
 }
 
 // Attr is an ordered key-value pair from a log line.
+// Value is a string, [json.Number], bool, nil, []any, or a [Group] for
+// nested objects.
 type Attr struct {
 	Key   string
 	Value any
 }
+
+// Group is a nested object whose attrs keep the order they were written in.
+type Group []Attr
+
+// MarshalJSON encodes g as a JSON object, preserving key order.
+func (g Group) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, a := range g {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		key, err := json.Marshal(a.Key)
+		if err != nil {
+			return nil, fmt.Errorf("marshalling key %q: %w", a.Key, err)
+		}
+		b.Write(key)
+		b.WriteByte(':')
+		val, err := json.Marshal(a.Value)
+		if err != nil {
+			return nil, fmt.Errorf("marshalling value of %q: %w", a.Key, err)
+		}
+		b.Write(val)
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
@@ -0,0 +1,37 @@
+package spretty_test
+
+import (
+	"testing"
+	"time"
+
+	spretty "github.com/mickamy/slog-pretty"
+)
+
+func TestRecord_Reset(t *testing.T) {
+	t.Parallel()
+
+	r := spretty.Record{
+		Time:    time.Now(),
+		Level:   "INFO",
+		Message: "hello",
+		Source:  &spretty.Source{File: "main.go", Line: 1},
+		Attrs:   []spretty.Attr{{Key: "a", Value: "b"}},
+		Labels:  []string{"api"},
+	}
+	attrs, labels := r.Attrs, r.Labels
+
+	r.Reset()
+
+	if !r.Time.IsZero() || r.Level != "" || r.Message != "" || r.Source != nil {
+		t.Errorf("Reset() left fields set: %+v", r)
+	}
+	if len(r.Attrs) != 0 || cap(r.Attrs) != cap(attrs) {
+		t.Errorf("Attrs len = %d cap = %d, want 0 and %d", len(r.Attrs), cap(r.Attrs), cap(attrs))
+	}
+	if len(r.Labels) != 0 || cap(r.Labels) != cap(labels) {
+		t.Errorf("Labels len = %d cap = %d, want 0 and %d", len(r.Labels), cap(r.Labels), cap(labels))
+	}
+	if attrs[0].Value != nil {
+		t.Errorf("Reset() kept a reference to old attr value %v", attrs[0].Value)
+	}
+}
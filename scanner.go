@@ -2,47 +2,119 @@ package spretty
 
 import (
 	"bufio"
+	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
 )
 
-const maxLineSize = 1024 * 1024 // 1MB
+const defaultMaxLineSize = 1024 * 1024 // 1MB
+
+// readBufferSize is the size of the buffer input is read through.
+const readBufferSize = 64 * 1024
+
+// maxPrefixAttempts bounds how many '{' positions are tried when looking for
+// a JSON record after a line prefix.
+const maxPrefixAttempts = 4
 
 var newline = []byte("\n") //nolint:gochecknoglobals // constant byte slice
 
-// Scanner reads lines from an io.Reader, formats slog JSON lines,
-// and writes the output to an io.Writer. Non-JSON lines are passed through.
-// Lines exceeding 1MB emit a truncation warning.
+// Scanner reads lines from an io.Reader, formats slog JSON and logfmt lines,
+// and writes the output to an io.Writer. Other lines are passed through.
+// JSON records following a prefix, such as the "web-1  | " that
+// docker compose prepends, are formatted with the prefix as a label, and
+// pretty-printed JSON objects spanning several lines are reassembled.
+// Docker json-file and Kubernetes CRI log files are unwrapped, joining lines
+// the runtime split, and records written to stderr are marked.
+// Input that starts with a JSON array of records is read one element at a
+// time.
+// JSON lines exceeding the line size limit (1MB unless set with
+// [WithMaxLineSize]) are formatted from their first part; other lines that
+// long are replaced by a warning. With [WithExplain], lines
+// passed through are annotated with the reason they were not formatted.
+//
+// A Scanner must not be used by multiple goroutines at once.
 type Scanner struct {
+	parser    *Parser
 	formatter *Formatter
+
+	// labelWidth is the widest prefix label seen so far, used to align labels.
+	labelWidth int
+
+	// pending collects the lines of a JSON object spanning several lines.
+	pending []byte
+	depth   depthTracker
+
+	// joined collects the partial entries of a container log line, for
+	// stdout and stderr apart.
+	joined [2]containerLine
+
+	// source labels every line, in sourceColor, with the input it came from
+	// when merging.
+	source      string
+	sourceColor Color
+	// lastTime is the time of the latest record, which lines without a time
+	// of their own are merged at.
+	lastTime time.Time
+
+	// lineSize is the full length of the line last read, which may have
+	// been cut short.
+	lineSize int
+
+	// line, rec, dec and out are reused from line to line.
+	line []byte
+	rec  Record
+	dec  decoder
+	out  bytes.Buffer
 }
 
 // NewScanner creates a Scanner with the given options.
 func NewScanner(opts ...Option) *Scanner {
-	return &Scanner{formatter: NewFormatter(opts...)}
+	cfg := newConfig(opts)
+	return &Scanner{
+		parser:    newParser(cfg),
+		formatter: &Formatter{cfg: cfg},
+		dec:       decoder{keys: make(map[string]string)},
+	}
 }
 
 // Scan reads from r and writes formatted output to w.
-// It processes input line-by-line and returns any I/O error encountered.
+// It processes input line-by-line, reassembling JSON objects that span
+// several lines, and returns any I/O error encountered.
 func (s *Scanner) Scan(r io.Reader, w io.Writer) error {
-	br := bufio.NewReaderSize(r, maxLineSize)
+	br := bufio.NewReaderSize(r, readBufferSize)
+	s.pending = nil
+	for i := range s.joined {
+		s.joined[i].partial = false
+	}
+
+	if startsArray(br) {
+		if err := s.scanArray(br, w); err != nil {
+			return err
+		}
+	}
 
 	for {
-		line, err := readLine(br)
+		line, err := s.readLine(br)
 
 		// EOF with no data: we're done.
 		if err != nil && errors.Is(err, io.EOF) && len(line) == 0 {
-			return nil
+			return s.flush(w)
 		}
 
-		if writeErr := s.processLine(w, line); writeErr != nil {
+		if writeErr := s.feedLine(w, line); writeErr != nil {
 			return writeErr
 		}
 
 		if err != nil {
+			if flushErr := s.flush(w); flushErr != nil {
+				return flushErr
+			}
 			if errors.Is(err, io.EOF) {
 				return nil
 			}
@@ -51,11 +123,25 @@ func (s *Scanner) Scan(r io.Reader, w io.Writer) error {
 	}
 }
 
-func readLine(br *bufio.Reader) ([]byte, error) {
-	var buf []byte
+// flush writes out lines held back at the end of input.
+func (s *Scanner) flush(w io.Writer) error {
+	if err := s.flushPending(w); err != nil {
+		return err
+	}
+	return s.flushJoined(w)
+}
+
+// readLine reads the next line into the Scanner's reusable line buffer.
+// Lines longer than the size limit are cut short, with lineSize recording
+// their full length.
+func (s *Scanner) readLine(br *bufio.Reader) ([]byte, error) {
+	buf := s.line[:0]
+	defer func() { s.line = buf }()
+	limit := s.maxLineSize()
 	for {
 		fragment, isPrefix, err := br.ReadLine()
 		buf = append(buf, fragment...)
+		s.lineSize = len(buf)
 
 		if !isPrefix || err != nil {
 			if err != nil {
@@ -64,12 +150,13 @@ func readLine(br *bufio.Reader) ([]byte, error) {
 			return buf, err
 		}
 
-		if len(buf) > maxLineSize {
+		if len(buf) > limit {
 			// Discard the rest of the oversized line.
 			for isPrefix && err == nil {
-				_, isPrefix, err = br.ReadLine()
+				fragment, isPrefix, err = br.ReadLine()
+				s.lineSize += len(fragment)
 			}
-			if err != nil {
+			if err != nil && !errors.Is(err, io.EOF) {
 				return buf, fmt.Errorf("discarding oversized line: %w", err)
 			}
 			return buf, nil
@@ -77,20 +164,121 @@ func readLine(br *bufio.Reader) ([]byte, error) {
 	}
 }
 
+// maxLineSize returns the configured line size limit.
+func (s *Scanner) maxLineSize() int {
+	return s.formatter.cfg.maxLineSize
+}
+
 func (s *Scanner) processLine(w io.Writer, line []byte) error {
-	if len(line) > maxLineSize {
-		return s.writeOverflow(w, line)
+	if len(line) > s.maxLineSize() {
+		return s.processOversized(w, line, s.lineSize)
 	}
 
-	rec, ok := Parse(line)
-	if ok {
-		_, err := fmt.Fprintln(w, s.formatter.Format(rec))
-		if err != nil {
-			return fmt.Errorf("writing formatted line: %w", err)
+	rec := &s.rec
+	if err := s.parse(line, rec); err != nil {
+		return s.passThrough(w, line, err)
+	}
+	return s.emit(w, rec)
+}
+
+// parse parses line into rec, trying a JSON record after a line prefix if
+// the whole line is not a record.
+func (s *Scanner) parse(line []byte, rec *Record) error {
+	err := s.parser.parseInto(line, rec, &s.dec)
+	if err == nil {
+		// Align labels the parser found, such as journald unit names.
+		for i, l := range rec.Labels {
+			rec.Labels[i] = s.padLabel(l, 0)
+		}
+		return nil
+	}
+	if s.parsePrefixed(line, rec) == nil {
+		return nil
+	}
+	return err
+}
+
+// emit writes rec unless it falls under the minimum level.
+func (s *Scanner) emit(w io.Writer, rec *Record) error {
+	if !rec.Time.IsZero() {
+		s.lastTime = rec.Time
+	}
+	if s.below(rec) {
+		return nil
+	}
+	return s.writeRecord(w, rec)
+}
+
+// passThrough writes a line that is not a record unchanged, explaining why
+// when enabled.
+func (s *Scanner) passThrough(w io.Writer, line []byte, err error) error {
+	if s.source != "" {
+		s.out.Reset()
+		s.formatter.writeLabel(&s.out, s.source, s.sourceColor)
+		if _, err := w.Write(s.out.Bytes()); err != nil {
+			return fmt.Errorf("writing passthrough label: %w", err)
 		}
+	}
+	if rawErr := writeRaw(w, line); rawErr != nil {
+		return rawErr
+	}
+	if len(bytes.TrimSpace(line)) == 0 {
+		return nil
+	}
+	return s.explain(w, err)
+}
+
+// explain writes a note saying why the line just passed through was not a
+// record, if enabled.
+func (s *Scanner) explain(w io.Writer, err error) error {
+	var perr *ParseError
+	if !s.formatter.cfg.explain || !errors.As(err, &perr) {
 		return nil
 	}
 
+	s.out.Reset()
+	s.out.WriteString(s.formatter.cfg.indent)
+	note := s.formatter.cfg.theme.Note
+	s.formatter.setColor(&s.out, note)
+	s.out.WriteString("[spretty] not a record: ")
+	s.out.WriteString(perr.Reason)
+	if perr.Offset >= 0 {
+		fmt.Fprintf(&s.out, " at byte %d", perr.Offset)
+	}
+	s.formatter.resetColor(&s.out, note)
+	s.out.WriteByte('\n')
+	if _, err := w.Write(s.out.Bytes()); err != nil {
+		return fmt.Errorf("writing explanation: %w", err)
+	}
+	return nil
+}
+
+// writeRecord formats rec into the Scanner's reusable output buffer and
+// writes it as one line.
+func (s *Scanner) writeRecord(w io.Writer, rec *Record) error {
+	s.out.Reset()
+	if s.source != "" {
+		s.formatter.writeLabel(&s.out, s.source, s.sourceColor)
+	}
+	s.formatter.writeRecord(&s.out, rec)
+	s.out.WriteByte('\n')
+	if _, err := w.Write(s.out.Bytes()); err != nil {
+		return fmt.Errorf("writing formatted line: %w", err)
+	}
+	return nil
+}
+
+// below reports whether rec falls under the configured minimum level.
+func (s *Scanner) below(rec *Record) bool {
+	if s.formatter.cfg.minLevel == nil {
+		return false
+	}
+	l, ok := rec.Severity()
+	return ok && l < s.formatter.cfg.minLevel.Level()
+}
+
+// writeRaw writes line unchanged, followed by a newline.
+func writeRaw(w io.Writer, line []byte) error {
 	// Write raw bytes to preserve non-UTF-8 content.
 	if _, err := w.Write(line); err != nil {
 		return fmt.Errorf("writing passthrough line: %w", err)
@@ -101,19 +289,74 @@ func (s *Scanner) processLine(w io.Writer, line []byte) error {
 	return nil
 }
 
-//nolint:gosec // output is log text, not user-facing HTML
-func (s *Scanner) writeOverflow(w io.Writer, line []byte) error {
+// parsePrefixed looks for a JSON record that follows an arbitrary prefix and
+// turns the prefix into a label.
+func (s *Scanner) parsePrefixed(line []byte, rec *Record) error {
+	i := bytes.IndexByte(line, '{')
+	for attempt := 0; i > 0 && attempt < maxPrefixAttempts; attempt++ {
+		if err := s.parser.parseInto(line[i:], rec, &s.dec); err == nil {
+			if label, width := prefixLabel(line[:i]); label != "" {
+				rec.Labels = append(rec.Labels, s.padLabel(label, width))
+			}
+			return nil
+		}
+		next := bytes.IndexByte(line[i+1:], '{')
+		if next < 0 {
+			break
+		}
+		i += next + 1
+	}
+	return ErrNotRecord
+}
+
+// prefixLabel strips the separator that usually ends a line prefix, so that
+// "web-1  | " becomes "web-1". width keeps any padding the producer put in
+// front of the separator, letting already aligned prefixes stay aligned.
+func prefixLabel(prefix []byte) (string, int) {
+	p := strings.TrimSpace(string(prefix))
+	if t := strings.TrimRight(p, "|:"); t != p {
+		p = strings.TrimSuffix(t, " ")
+	}
+	label := strings.TrimSpace(p)
+	return label, utf8.RuneCountInString(p)
+}
+
+// padLabel pads label to the widest label seen so far so that records
+// from different prefixes line up.
+func (s *Scanner) padLabel(label string, width int) string {
+	n := utf8.RuneCountInString(label)
+	s.labelWidth = max(s.labelWidth, width, n)
+	return label + strings.Repeat(" ", s.labelWidth-n)
+}
+
+// processOversized formats a JSON line of n bytes that exceeds the line
+// size limit from the part of it that was read, shortening large values.
+// Other oversized lines are replaced by a warning.
+func (s *Scanner) processOversized(w io.Writer, line []byte, n int) error {
+	limit := s.maxLineSize()
+	line = line[:min(len(line), limit)]
+
+	rec := &s.rec
+	if err := s.parser.parseTruncated(line, rec, &s.dec); err != nil {
+		return s.writeOverflow(w, n)
+	}
+	rec.Attrs = append(rec.Attrs, Attr{
+		Key:   truncatedKey,
+		Value: fmt.Sprintf("showing %d of %d bytes", limit, n),
+	})
+	return s.emit(w, rec)
+}
+
+// writeOverflow writes a warning in place of a line of n bytes that exceeds
+// the line size limit.
+func (s *Scanner) writeOverflow(w io.Writer, n int) error {
 	rec := &Record{
 		Level:   "WARN",
 		Message: "[spretty] line truncated",
 		Attrs: []Attr{
-			{Key: "max_bytes", Value: json.Number(strconv.Itoa(maxLineSize))},
-			{Key: "read_bytes", Value: json.Number(strconv.Itoa(len(line)))},
+			{Key: "max_bytes", Value: json.Number(strconv.Itoa(s.maxLineSize()))},
+			{Key: "read_bytes", Value: json.Number(strconv.Itoa(n))},
 		},
 	}
-	_, err := fmt.Fprintln(w, s.formatter.Format(rec))
-	if err != nil {
-		return fmt.Errorf("writing overflow line: %w", err)
-	}
-	return nil
+	return s.writeRecord(w, rec)
 }
@@ -2,6 +2,8 @@ package spretty_test
 
 import (
 	"bytes"
+	"io"
+	"log/slog"
 	"strings"
 	"testing"
 
@@ -70,6 +72,161 @@ not json
 			contains: []string{"INFO", "a", "WARN", "b"},
 			lines:    2,
 		},
+		{
+			name: "TextHandler lines",
+			input: `time=2026-02-26T10:15:30Z level=INFO msg="server started" port=8080
+time=2026-02-26T10:15:31Z level=ERROR msg=fail req.method=GET
+`,
+			opts:     []spretty.Option{spretty.WithNoColor()},
+			contains: []string{"10:15:30.000 INFO  server started", "port=8080", "ERROR fail", "req=", "method=GET"},
+			excludes: []string{"level=", "msg="},
+			lines:    2,
+		},
+		{
+			name: "JSON after docker compose prefix",
+			input: `api-1     | {"time":"2026-02-26T10:15:30Z","level":"INFO","msg":"hello","port":8080}
+worker-12 | {"time":"2026-02-26T10:15:31Z","level":"WARN","msg":"slow"}
+`,
+			opts: []spretty.Option{spretty.WithNoColor()},
+			contains: []string{
+				"api-1     | 10:15:30.000 INFO  hello",
+				"worker-12 | 10:15:31.000 WARN  slow",
+				"port=8080",
+			},
+			lines: 2,
+		},
+		{
+			name:     "JSON after timestamp prefix",
+			input:    `2026-02-26T10:15:30.000000000Z {"level":"INFO","msg":"hello"}` + "\n",
+			opts:     []spretty.Option{spretty.WithNoColor()},
+			contains: []string{"2026-02-26T10:15:30.000000000Z | INFO  hello"},
+			lines:    1,
+		},
+		{
+			name:     "text with braces passes through",
+			input:    "error: {not json} {still not}\n",
+			opts:     []spretty.Option{spretty.WithNoColor()},
+			contains: []string{"error: {not json} {still not}\n"},
+			lines:    1,
+		},
+		{
+			name: "pretty-printed JSON spanning lines",
+			input: `{
+  "time": "2026-02-26T10:15:30Z",
+  "level": "INFO",
+  "msg": "hello } {",
+  "params": {
+    "table": "users"
+  }
+}
+after
+`,
+			opts:     []spretty.Option{spretty.WithNoColor()},
+			contains: []string{"10:15:30.000 INFO  hello } {\n  params=\n    table=users\nafter\n"},
+			excludes: []string{`"level"`},
+		},
+		{
+			name:     "unclosed object passes through at EOF",
+			input:    "{\n  \"level\": \"INFO\",\n  \"msg\": \"cut off\"\n",
+			opts:     []spretty.Option{spretty.WithNoColor()},
+			contains: []string{"{\n  \"level\": \"INFO\",\n  \"msg\": \"cut off\"\n"},
+		},
+		{
+			name:     "multi-line block that is not JSON passes through",
+			input:    "{\n  not json\n}\n{\"level\":\"INFO\",\"msg\":\"next\"}\n",
+			opts:     []spretty.Option{spretty.WithNoColor()},
+			contains: []string{"{\n  not json\n}\nINFO  next\n"},
+		},
+		{
+			name: "pretty-printed record ending in a nested record-like object",
+			input: `{
+  "level": "INFO",
+  "msg": "outer",
+  "req": {"msg":"inner","level":"WARN"}
+}
+`,
+			opts:     []spretty.Option{spretty.WithNoColor()},
+			contains: []string{"INFO  outer\n  req=\n    msg=inner\n    level=WARN\n"},
+			excludes: []string{"|", `"level"`},
+		},
+		{
+			name: "pretty-printed record ending in an array of record-like objects",
+			input: `{
+  "level": "INFO",
+  "msg": "outer",
+  "events": [
+    {"time":"2026-02-26T10:15:30Z","msg":"x"},
+    {"time":"2026-02-26T10:15:31Z","msg":"y"}
+  ]
+}
+`,
+			opts:     []spretty.Option{spretty.WithNoColor()},
+			contains: []string{"INFO  outer\n  events=\n    [0]=\n      time=2026-02-26T10:15:30Z\n      msg=x\n"},
+			excludes: []string{"|", `"level"`},
+		},
+		{
+			name: "pretty-printed record larger than 64KB within the line size limit",
+			input: "{\n  \"level\": \"INFO\",\n  \"msg\": \"big\",\n  \"items\": [\n" +
+				strings.Repeat("    \"xxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxxx\",\n", 1200) +
+				"    \"end\"\n  ]\n}\n",
+			opts:     []spretty.Option{spretty.WithNoColor(), spretty.WithMaxLineSize(10_000_000)},
+			contains: []string{"INFO  big\n  items=\n    [0]=xxx", "    [1200]=end"},
+			excludes: []string{`"level"`},
+		},
+		{
+			name: "truncated line followed by valid records",
+			input: `{"level":"INFO","msg":"start","req":{"id":1
+{"level":"INFO","msg":"one"}
+{"level":"WARN","msg":"two"}
+`,
+			opts: []spretty.Option{spretty.WithNoColor()},
+			contains: []string{
+				`{"level":"INFO","msg":"start","req":{"id":1` + "\nINFO  one\nWARN  two\n",
+			},
+		},
+		{
+			name: "truncated string followed by valid records",
+			input: `{"level":"INFO","msg":"crash
+{"level":"INFO","msg":"one"}
+level=WARN msg=two
+`,
+			opts:     []spretty.Option{spretty.WithNoColor()},
+			contains: []string{`{"level":"INFO","msg":"crash` + "\nINFO  one\nWARN  two\n"},
+		},
+		{
+			name:     "extra closing brace ends buffering",
+			input:    "{\n}}\n{\"level\":\"INFO\",\"msg\":\"next\"}\n",
+			opts:     []spretty.Option{spretty.WithNoColor()},
+			contains: []string{"{\n}}\nINFO  next\n"},
+		},
+		{
+			name: "minimum level",
+			input: `{"level":"DEBUG","msg":"noise"}
+{"level":"INFO+2","msg":"notice"}
+{"level":"WARN","msg":"careful"}
+{"level":"NOTICE","msg":"unknown"}
+{"level":"trace","msg":"chatter"}
+plain
+`,
+			opts:     []spretty.Option{spretty.WithNoColor(), spretty.WithMinLevel(slog.LevelInfo + 1)},
+			contains: []string{"INFO+2 notice", "WARN  careful", "NOTICE unknown", "plain"},
+			excludes: []string{"noise", "chatter"},
+			lines:    4,
+		},
+		{
+			name: "renamed core keys",
+			input: `{"ts":"2026-02-26T10:15:30Z","severity":"ERROR","message":"boom","code":500}
+`,
+			opts: []spretty.Option{
+				spretty.WithNoColor(),
+				spretty.WithTimeKey("ts"),
+				spretty.WithLevelKey("severity"),
+				spretty.WithMessageKey("message"),
+			},
+			contains: []string{"10:15:30.000", "ERROR", "boom", "code=500"},
+			excludes: []string{"severity", "message="},
+			lines:    1,
+		},
 		{
 			name:  "oversized line shows warning and continues",
 			input: strings.Repeat("x", 2*1024*1024) + "\n" + `{"level":"INFO","msg":"after"}` + "\n",
@@ -82,6 +239,207 @@ not json
 				"after",
 			},
 		},
+		{
+			name: "oversized JSON line keeps its core fields",
+			input: `{"time":"2026-02-26T10:15:30Z","level":"ERROR","msg":"dump","id":7,"body":"` +
+				strings.Repeat("x", 2*1024*1024) + `"}` + "\n" + `{"level":"INFO","msg":"after"}` + "\n",
+			opts: []spretty.Option{spretty.WithNoColor()},
+			contains: []string{
+				"10:15:30.000 ERROR dump\n  id=7\n  body=" + strings.Repeat("x", 1024) + "…\n",
+				"[spretty] truncated=showing 1048576 of 2097229 bytes\n",
+				"INFO  after",
+			},
+			excludes: []string{"line truncated", strings.Repeat("x", 1025)},
+		},
+		{
+			name: "large values within the limit are shortened",
+			input: `{"level":"WARN","msg":"big","blob":"` + strings.Repeat("y", 2000) + `","list":[` +
+				strings.Repeat("1,", 600) + `1],"ok":true,"cut":{"a":"` + strings.Repeat("z", 5000) + `"}}` + "\n",
+			opts: []spretty.Option{spretty.WithNoColor(), spretty.WithMaxLineSize(5000)},
+			contains: []string{
+				"WARN  big\n",
+				"  blob=" + strings.Repeat("y", 1024) + "… (2000 bytes)\n",
+				"  list=[…] (1203 bytes)\n",
+				"  ok=true\n",
+				"  cut={…}\n",
+				"[spretty] truncated=showing 5000 of 8274 bytes",
+			},
+		},
+		{
+			name:  "oversized line cut inside a number",
+			input: `{"level":"INFO","msg":"n","big":` + strings.Repeat("9", 100) + "}\n",
+			opts:  []spretty.Option{spretty.WithNoColor(), spretty.WithMaxLineSize(50)},
+			contains: []string{
+				"INFO  n\n  big=" + strings.Repeat("9", 18) + "…\n",
+				"showing 50 of 133 bytes",
+			},
+		},
+		{
+			name:     "oversized text line shows warning with the configured limit",
+			input:    strings.Repeat("t", 100) + "\n",
+			opts:     []spretty.Option{spretty.WithNoColor(), spretty.WithMaxLineSize(64)},
+			contains: []string{"[spretty] line truncated", "max_bytes=64", "read_bytes=100"},
+		},
+		{
+			name: "explain annotates passthrough lines",
+			input: `{"level":"INFO","msg":42}
+plain text
+
+{"level":"INFO","msg":"ok"}
+`,
+			opts: []spretty.Option{spretty.WithNoColor(), spretty.WithExplain()},
+			contains: []string{
+				`{"level":"INFO","msg":42}` + "\n  [spretty] not a record: msg: a number is not a string at byte 22\n",
+				"plain text\n  [spretty] not a record: expected key=value pair at byte 0\n\n",
+				"INFO  ok",
+			},
+			lines: 6,
+		},
+		{
+			name:     "explain unclosed object at EOF",
+			input:    "{\n  \"level\": \"INFO\",\n",
+			opts:     []spretty.Option{spretty.WithNoColor(), spretty.WithExplain()},
+			contains: []string{"[spretty] not a record: unexpected end of input, expected object key at byte 20"},
+		},
+		{
+			name: "journald entries",
+			input: `{"_SYSTEMD_UNIT":"api.service","PRIORITY":"6","MESSAGE":"{\"level\":\"INFO\",\"msg\":\"up\"}"}
+{"_SYSTEMD_UNIT":"db.service","PRIORITY":"3","MESSAGE":"disk full"}
+`,
+			opts: []spretty.Option{spretty.WithNoColor(), spretty.WithJournald()},
+			contains: []string{
+				"api.service | INFO  up\n",
+				"db.service  | ERROR disk full\n",
+			},
+		},
+		{
+			name: "Docker json-file entries",
+			input: `{"log":"{\"level\":\"INFO\",\"msg\":\"up\"}\n","stream":"stdout","time":"2026-02-26T10:15:30.5Z"}
+{"log":"{\"level\":\"ERROR\",\"msg\":\"boom\"}\n","stream":"stderr","time":"2026-02-26T10:15:31Z"}
+{"log":"plain output\n","stream":"stdout","time":"2026-02-26T10:15:32Z"}
+`,
+			opts: []spretty.Option{spretty.WithNoColor()},
+			contains: []string{
+				"10:15:30.500 INFO  up\n",
+				"stderr | 10:15:31.000 ERROR boom\n",
+				"\nplain output\n",
+			},
+			excludes: []string{`"log"`, "stdout |"},
+		},
+		{
+			name: "Docker partial entries are joined",
+			input: `{"log":"{\"level\":\"INFO\",","stream":"stdout","time":"2026-02-26T10:15:30Z"}
+{"log":"\"msg\":\"joined\"}\n","stream":"stdout","time":"2026-02-26T10:15:30Z"}
+`,
+			opts:     []spretty.Option{spretty.WithNoColor()},
+			contains: []string{"INFO  joined"},
+			excludes: []string{`"log"`},
+		},
+		{
+			name: "CRI entries with partial lines",
+			input: `2026-02-26T10:15:30.123456789Z stdout P {"level":"WARN",
+2026-02-26T10:15:30.123456789Z stdout F "msg":"slow"}
+2026-02-26T10:15:31Z stderr F {"time":"2026-02-26T10:00:00Z","level":"ERROR","msg":"failed"}
+2026-02-26T10:15:32Z stdout F plain output
+`,
+			opts: []spretty.Option{spretty.WithNoColor()},
+			contains: []string{
+				"10:15:30.123 WARN  slow\n",
+				"stderr | 10:00:00.000 ERROR failed\n",
+				"\nplain output\n",
+			},
+			excludes: []string{"stdout F"},
+		},
+		{
+			name: "CRI partial lines of interleaved streams",
+			input: `2026-02-26T10:15:30Z stdout P {"level":"INFO",
+2026-02-26T10:15:30Z stderr P {"level":"ERROR",
+2026-02-26T10:15:31Z stderr F "msg":"failed"}
+2026-02-26T10:15:31Z stdout F "msg":"joined"}
+`,
+			opts: []spretty.Option{spretty.WithNoColor()},
+			contains: []string{
+				"stderr | 10:15:30.000 ERROR failed\n10:15:30.000 INFO  joined\n",
+			},
+			excludes: []string{`{"level"`},
+		},
+		{
+			name: "Docker partial entries of interleaved streams",
+			input: `{"log":"{\"level\":\"INFO\",","stream":"stdout","time":"2026-02-26T10:15:30Z"}
+{"log":"{\"level\":\"ERROR\",\"msg\":\"failed\"}\n","stream":"stderr","time":"2026-02-26T10:15:30Z"}
+{"log":"\"msg\":\"joined\"}\n","stream":"stdout","time":"2026-02-26T10:15:30Z"}
+`,
+			opts:     []spretty.Option{spretty.WithNoColor()},
+			contains: []string{"stderr | 10:15:30.000 ERROR failed\n10:15:30.000 INFO  joined\n"},
+			excludes: []string{`"log"`},
+		},
+		{
+			name:     "unfinished CRI partial line is flushed at EOF",
+			input:    "2026-02-26T10:15:30Z stdout P {\"level\":\"INFO\",\"msg\":\"cut\"}\n",
+			opts:     []spretty.Option{spretty.WithNoColor()},
+			contains: []string{"INFO  cut"},
+		},
+		{
+			name:     "timestamped text is not a CRI entry",
+			input:    "2026-02-26T10:15:30Z INFO F started\n",
+			opts:     []spretty.Option{spretty.WithNoColor()},
+			contains: []string{"2026-02-26T10:15:30Z INFO F started"},
+		},
+		{
+			name: "JSON array of records",
+			input: `[
+  {
+    "time": "2026-02-26T10:15:30Z",
+    "level": "INFO",
+    "msg": "first, [with] brackets"
+  },
+  {"level":"ERROR","msg":"second","err":{"code":"E1"}},
+  42
+]
+after the array
+`,
+			opts: []spretty.Option{spretty.WithNoColor()},
+			contains: []string{
+				"10:15:30.000 INFO  first, [with] brackets\n",
+				"ERROR second\n  err=\n    code=E1\n",
+				"\n42\n",
+				"\nafter the array\n",
+			},
+			excludes: []string{"[\n", "]\n"},
+		},
+		{
+			name:     "single-line JSON array",
+			input:    `  [{"level":"INFO","msg":"a"},{"level":"WARN","msg":"b"}]` + "\n",
+			opts:     []spretty.Option{spretty.WithNoColor()},
+			contains: []string{"INFO  a\nWARN  b\n"},
+			excludes: []string{"["},
+		},
+		{
+			name:     "JSON array larger than the line limit",
+			input:    "[" + strings.Repeat(`{"level":"INFO","msg":"x"},`, 50000) + `{"level":"INFO","msg":"last"}]`,
+			opts:     []spretty.Option{spretty.WithNoColor()},
+			contains: []string{"INFO  x\n", "INFO  last\n"},
+			excludes: []string{"line truncated"},
+			lines:    50001,
+		},
+		{
+			name:     "bracketed text is not an array",
+			input:    "[INFO] starting\n",
+			opts:     []spretty.Option{spretty.WithNoColor()},
+			contains: []string{"[INFO] starting\n"},
+		},
+		{
+			name:     "truncated JSON array",
+			input:    `[{"level":"INFO","msg":"a"},{"level":"WA`,
+			opts:     []spretty.Option{spretty.WithNoColor()},
+			contains: []string{"INFO  a\n", `{"level":"WA`},
+		},
+		{
+			name:     "no annotation without explain",
+			input:    "plain text\n",
+			opts:     []spretty.Option{spretty.WithNoColor()},
+			excludes: []string{"not a record"},
+		},
 	}
 
 	for _, tt := range tests {
@@ -125,3 +483,18 @@ not json
 		})
 	}
 }
+
+func BenchmarkScanner_Scan(b *testing.B) {
+	line := `{"time":"2026-02-26T10:15:30.123Z","level":"INFO","msg":"request handled",` +
+		`"method":"GET","path":"/api/users","status":200}` + "\n"
+	input := strings.Repeat(line, 1000)
+	s := spretty.NewScanner(spretty.WithNoColor())
+
+	b.ReportAllocs()
+	b.SetBytes(int64(len(input)))
+	for b.Loop() {
+		if err := s.Scan(strings.NewReader(input), io.Discard); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,187 @@
+package spretty
+
+import (
+	"fmt"
+	"hash/fnv"
+	"log/slog"
+	"strings"
+)
+
+// Color is the ANSI escape sequence that styles a piece of output, such as
+// "\033[36m". An empty Color leaves text unstyled.
+type Color string
+
+// Color256 returns the foreground color n of the 256-color palette.
+func Color256(n uint8) Color {
+	return Color(fmt.Sprintf("\033[38;5;%dm", n))
+}
+
+// TrueColor returns a 24-bit foreground color.
+func TrueColor(r, g, b uint8) Color {
+	return Color(fmt.Sprintf("\033[38;2;%d;%d;%dm", r, g, b))
+}
+
+// Bold returns c in bold.
+func (c Color) Bold() Color {
+	return bold + c
+}
+
+// Theme holds the colors of every styled part of the output.
+type Theme struct {
+	Time Color
+
+	// Debug, Info, Warn and Error color levels at or above each base level;
+	// OtherLevel colors levels that are not recognized.
+	Debug      Color
+	Info       Color
+	Warn       Color
+	Error      Color
+	OtherLevel Color
+
+	Message Color
+	Source  Color
+	Key     Color
+	Equals  Color
+
+	// String, Number, Bool and Null color attr values by type.
+	String Color
+	Number Color
+	Bool   Color
+	Null   Color
+
+	// Labels color line prefix labels, each keeping a stable color, and are
+	// given in turn to the inputs of [Scanner.Merge].
+	Labels    []Color
+	Separator Color // the " | " after labels
+	Stderr    Color // the mark of records written to stderr
+	Note      Color // notes such as "(json)" and --explain annotations
+}
+
+// DarkTheme returns the default theme, for terminals with a dark background.
+func DarkTheme() Theme {
+	return Theme{
+		Time:       gray,
+		Debug:      blue,
+		Info:       green,
+		Warn:       yellow,
+		Error:      red,
+		OtherLevel: gray,
+		Message:    bold,
+		Source:     dim,
+		Key:        cyan,
+		Equals:     gray,
+		Labels:     []Color{cyan, magenta, yellow, green, blue},
+		Separator:  gray,
+		Stderr:     red,
+		Note:       dim,
+	}
+}
+
+// LightTheme returns a theme with darker colors for terminals with a light
+// background.
+func LightTheme() Theme {
+	return Theme{
+		Time:       Color256(240),
+		Debug:      Color256(25),
+		Info:       Color256(28),
+		Warn:       Color256(130),
+		Error:      Color256(160),
+		OtherLevel: Color256(240),
+		Message:    bold,
+		Source:     Color256(243),
+		Key:        Color256(24),
+		Equals:     Color256(243),
+		Number:     Color256(90),
+		Bool:       Color256(90),
+		Null:       Color256(243),
+		Labels:     []Color{Color256(24), Color256(90), Color256(130), Color256(28), Color256(25)},
+		Separator:  Color256(243),
+		Stderr:     Color256(160),
+		Note:       Color256(243),
+	}
+}
+
+// HighContrastTheme returns a theme of bright, bold colors.
+func HighContrastTheme() Theme {
+	const (
+		brightRed     Color = "\033[91m"
+		brightGreen   Color = "\033[92m"
+		brightYellow  Color = "\033[93m"
+		brightBlue    Color = "\033[94m"
+		brightMagenta Color = "\033[95m"
+		brightCyan    Color = "\033[96m"
+		white         Color = "\033[97m"
+	)
+	return Theme{
+		Time:       white,
+		Debug:      brightBlue.Bold(),
+		Info:       brightGreen.Bold(),
+		Warn:       brightYellow.Bold(),
+		Error:      brightRed.Bold(),
+		OtherLevel: white.Bold(),
+		Message:    white.Bold(),
+		Source:     white,
+		Key:        brightCyan.Bold(),
+		Equals:     white,
+		String:     white,
+		Number:     brightMagenta,
+		Bool:       brightMagenta,
+		Null:       brightMagenta,
+		Labels:     []Color{brightCyan, brightMagenta, brightYellow, brightGreen, brightBlue},
+		Separator:  white,
+		Stderr:     brightRed.Bold(),
+		Note:       brightYellow,
+	}
+}
+
+// themeNames lists the built-in themes by name.
+var themeNames = map[string]func() Theme{ //nolint:gochecknoglobals // constant lookup table
+	"dark":          DarkTheme,
+	"light":         LightTheme,
+	"high-contrast": HighContrastTheme,
+}
+
+// ParseTheme returns the built-in theme with the given name: "dark", "light"
+// or "high-contrast".
+func ParseTheme(name string) (Theme, error) {
+	if theme, ok := themeNames[strings.ToLower(name)]; ok {
+		return theme(), nil
+	}
+	return DarkTheme(), fmt.Errorf("unknown theme: %q", name)
+}
+
+// level picks the color of the base level at or below level, so that
+// offsets such as "INFO+2" share their base level's color.
+func (t *Theme) level(level string) Color {
+	l, ok := levelSeverity(level)
+	switch {
+	case !ok:
+		return t.OtherLevel
+	case l >= slog.LevelError:
+		return t.Error
+	case l >= slog.LevelWarn:
+		return t.Warn
+	case l >= slog.LevelInfo:
+		return t.Info
+	default:
+		return t.Debug
+	}
+}
+
+// labelAt picks the i-th label color, cycling through the palette.
+func (t *Theme) labelAt(i int) Color {
+	if len(t.Labels) == 0 {
+		return ""
+	}
+	return t.Labels[i%len(t.Labels)]
+}
+
+// label picks a color for label that stays the same from line to line.
+func (t *Theme) label(label string) Color {
+	if len(t.Labels) == 0 {
+		return ""
+	}
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(label))
+	return t.Labels[h.Sum32()%uint32(len(t.Labels))]
+}
@@ -0,0 +1,134 @@
+package spretty_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	spretty "github.com/mickamy/slog-pretty"
+)
+
+func TestColor(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		color spretty.Color
+		want  spretty.Color
+	}{
+		{name: "256-color", color: spretty.Color256(208), want: "\033[38;5;208m"},
+		{name: "truecolor", color: spretty.TrueColor(255, 128, 0), want: "\033[38;2;255;128;0m"},
+		{name: "bold", color: spretty.Color256(1).Bold(), want: "\033[1m\033[38;5;1m"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if tt.color != tt.want {
+				t.Errorf("color = %q, want %q", tt.color, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTheme(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		want    spretty.Theme
+		wantErr bool
+	}{
+		{name: "dark", want: spretty.DarkTheme()},
+		{name: "Light", want: spretty.LightTheme()},
+		{name: "high-contrast", want: spretty.HighContrastTheme()},
+		{name: "solarized", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := spretty.ParseTheme(tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseTheme(%q) error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			}
+			if !tt.wantErr && (got.Info != tt.want.Info || got.Key != tt.want.Key) {
+				t.Errorf("ParseTheme(%q) = %+v, want %+v", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTheme_LevelColor(t *testing.T) {
+	t.Parallel()
+
+	dark := spretty.DarkTheme()
+	light := spretty.LightTheme()
+	tests := []struct {
+		name  string
+		theme spretty.Theme
+		level string
+		want  spretty.Color
+	}{
+		{name: "TRACE", theme: dark, level: "TRACE", want: "\033[34m"},
+		{name: "DEBUG", theme: dark, level: "DEBUG", want: "\033[34m"},
+		{name: "INFO", theme: dark, level: "INFO", want: "\033[32m"},
+		{name: "WARN", theme: dark, level: "WARN", want: "\033[33m"},
+		{name: "ERROR", theme: dark, level: "ERROR", want: "\033[31m"},
+		{name: "DEBUG offset", theme: dark, level: "DEBUG-4", want: "\033[34m"},
+		{name: "INFO offset", theme: dark, level: "INFO+2", want: "\033[32m"},
+		{name: "WARN offset", theme: dark, level: "WARN+1", want: "\033[33m"},
+		{name: "ERROR offset", theme: dark, level: "ERROR+4", want: "\033[31m"},
+		{name: "FATAL", theme: dark, level: "FATAL", want: "\033[31m"},
+		{name: "unknown level", theme: dark, level: "NOTICE", want: "\033[90m"},
+		{name: "light INFO", theme: light, level: "INFO", want: light.Info},
+		{name: "light unknown level", theme: light, level: "NOTICE", want: light.OtherLevel},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			rec := spretty.Record{Level: tt.level}
+			got := spretty.NewFormatter(spretty.WithTheme(tt.theme)).Format(&rec)
+			if want := string(tt.want) + tt.level; !strings.HasPrefix(got, want) {
+				t.Errorf("Format() = %q, want prefix %q", got, want)
+			}
+		})
+	}
+}
+
+func TestFormatter_Theme(t *testing.T) {
+	t.Parallel()
+
+	theme := spretty.Theme{
+		Info:   spretty.TrueColor(0, 200, 0),
+		Key:    spretty.Color256(33),
+		Number: spretty.Color256(141),
+		Null:   spretty.Color256(244),
+	}
+	rec := spretty.Record{
+		Level:   "INFO",
+		Message: "hello",
+		Attrs: []spretty.Attr{
+			{Key: "n", Value: json.Number("1")},
+			{Key: "err", Value: nil},
+			{Key: "s", Value: "text"},
+		},
+	}
+
+	got := spretty.NewFormatter(spretty.WithTheme(theme)).Format(&rec)
+
+	for _, want := range []string{
+		"\033[38;2;0;200;0mINFO \033[0m hello\n",
+		"\033[38;5;33mn\033[0m=\033[38;5;141m1\033[0m",
+		"\033[38;5;33merr\033[0m=\033[38;5;244mnull\033[0m",
+		"\033[38;5;33ms\033[0m=text",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output missing %q\ngot: %q", want, got)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package spretty
+
+import (
+	"bytes"
+	"fmt"
+	"unicode/utf8"
+)
+
+// maxValueSize is the most bytes of a single value shown from an oversized
+// line.
+const maxValueSize = 1024
+
+// truncatedKey is the attr that notes a record was read from part of a line.
+const truncatedKey = "[spretty] truncated"
+
+// parseTruncated parses the first part of a JSON line that was cut short
+// for being too long. It keeps every field read before the cut, shortens
+// values longer than maxValueSize, and marks a value the cut went through.
+func (p *Parser) parseTruncated(line []byte, rec *Record, d *decoder) error {
+	rec.Reset()
+
+	start := len(line) - len(bytes.TrimLeft(line, " \t\r\n"))
+	if start == len(line) || line[start] != '{' {
+		return parseErrorf(start, "oversized line is not a JSON object")
+	}
+	d.reset(line)
+	d.pos = start + 1
+
+	var slots fieldSlots
+	for d.peek() != '}' {
+		kb, err := d.keyBytes()
+		if err != nil {
+			if d.pos < len(d.data) {
+				return err
+			}
+			break // cut inside a key
+		}
+		key := d.intern(kb)
+
+		d.skipSpace()
+		valStart := d.pos
+		v, err := d.value()
+		switch {
+		case err != nil && d.pos < len(d.data):
+			return err
+		case err != nil, d.pos == len(d.data) && !delimited(d.data[valStart]):
+			// The cut went through this value.
+			v = cutValue(d.data[valStart:])
+		case d.pos-valStart > maxValueSize:
+			v = shortenValue(v, d.data[valStart:d.pos])
+		}
+
+		sl := slot{key: key, val: v, off: valStart}
+		if s, ok := v.(string); ok {
+			sl = slot{key: key, str: s, isStr: true, off: valStart}
+		}
+		f, _, rank, core := lookupKey(&p.dialect, key)
+		if !core || !p.claim(rec, &slots, f, rank, sl) {
+			rec.Attrs = append(rec.Attrs, Attr{Key: key, Value: v})
+		}
+
+		if more, err := d.more('}'); err != nil || !more {
+			break
+		}
+	}
+
+	return p.finishRecord(rec, &slots)
+}
+
+// delimited reports whether a JSON value starting with c has a closing
+// delimiter, which shows that it was read in full.
+func delimited(c byte) bool {
+	return c == '"' || c == '{' || c == '['
+}
+
+// cutValue describes the start of a JSON value that was cut off.
+func cutValue(raw []byte) any {
+	if len(raw) == 0 {
+		return "…"
+	}
+	switch raw[0] {
+	case '"':
+		return truncateString(string(raw[1:])) + "…"
+	case '{':
+		return "{…}"
+	case '[':
+		return "[…]"
+	default:
+		return string(raw) + "…"
+	}
+}
+
+// shortenValue replaces a value whose JSON form raw is longer than
+// maxValueSize with a short description of it.
+func shortenValue(v any, raw []byte) any {
+	switch v := v.(type) {
+	case string:
+		if len(v) <= maxValueSize {
+			return v
+		}
+		return fmt.Sprintf("%s… (%d bytes)", truncateString(v), len(v))
+	case Group:
+		return fmt.Sprintf("{…} (%d bytes)", len(raw))
+	case []any:
+		return fmt.Sprintf("[…] (%d bytes)", len(raw))
+	default:
+		return v
+	}
+}
+
+// truncateString cuts s to at most maxValueSize bytes without splitting a
+// UTF-8 sequence.
+func truncateString(s string) string {
+	if len(s) <= maxValueSize {
+		return s
+	}
+	n := maxValueSize
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}